@@ -0,0 +1,107 @@
+package getting
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/identbase/getting/pkg/cache"
+	"github.com/identbase/getting/pkg/resource"
+)
+
+/*
+WithCache stores responses in c, and serves GET requests from it while the
+stored responses are fresh. */
+func WithCache(c cache.Cache) Option {
+	return func(g *Getting) {
+		g.cache = c
+	}
+}
+
+/*
+cached serves a GET request from the cache, revalidating stale entries with
+the server, and stores new responses. */
+func (g *Getting) cached(req *http.Request) (*http.Response, error) {
+	u := req.URL.String()
+
+	e, ok := g.cache.Get(u, req.Header)
+	if ok && e.Fresh(time.Now()) {
+		g.emit(Event{
+			Type:    EventCacheHit,
+			Request: req,
+		})
+
+		return e.Response(req), nil
+	}
+
+	creq := req
+	if ok && (e.ETag != "" || e.LastModified != "") {
+		creq = req.Clone(req.Context())
+		if e.ETag != "" {
+			creq.Header.Set("If-None-Match", e.ETag)
+		}
+		if e.LastModified != "" {
+			creq.Header.Set("If-Modified-Since", e.LastModified)
+		}
+	}
+
+	resp, err := g.retry(creq)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+
+		// The entry is shared with concurrent requests, update a copy.
+		e = e.Clone()
+		for k, v := range resp.Header {
+			e.Header[k] = v
+		}
+		e.Stored = time.Now()
+		g.cache.Set(e)
+
+		g.emit(Event{
+			Type:    EventCacheRevalidated,
+			Request: req,
+		})
+
+		return e.Response(req), nil
+	}
+
+	if !cache.Storable(resp) {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	// A redirected response is stored under the uri it came from, the uri it
+	// was requested with may redirect somewhere else next time.
+	e = cache.NewEntry(resp, body)
+	for n := range e.Vary {
+		e.Vary[n] = req.Header.Get(n)
+	}
+
+	// Failing to store a response does not fail the request.
+	g.cache.Set(e)
+
+	// Permanent redirects are followed from the cache as well, temporary
+	// ones go to the server again.
+	c := resource.Redirects(resp)
+	if to, ok := resource.PermanentLocation(c); ok && to.String() == e.URI {
+		p := e.Clone()
+		p.URI = u
+		p.Location = e.URI
+		p.Redirect = c[0].StatusCode
+		g.cache.Set(p)
+	}
+
+	return resp, nil
+}
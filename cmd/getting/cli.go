@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/identbase/getting"
+	"github.com/identbase/getting/pkg/auth"
+	"github.com/identbase/getting/pkg/crawl"
+	"github.com/identbase/getting/pkg/linkcheck"
+	"github.com/identbase/getting/pkg/resource"
+)
+
+/*
+cli holds the state of one invocation. */
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	format      string
+	contentType string
+	bearer      string
+	depth       int
+}
+
+/*
+run parses the arguments, runs the command and returns the exit code. */
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	c := cli{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	fs := flag.NewFlagSet("getting", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.format, "format", "table", "output format, json or table")
+	fs.StringVar(&c.contentType, "type", "application/json", "content-type of the put and post body")
+	fs.StringVar(&c.bearer, "bearer", "", "bearer token sent to the origin of the uri")
+	fs.IntVar(&c.depth, "depth", 0, "maximum depth of the graph and check commands, 0 is unlimited")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: getting [flags] <command> <uri> [arguments]")
+		fmt.Fprintln(stderr, "commands: get, links, follow, embedded, put, post, shell, graph, check")
+		fs.PrintDefaults()
+	}
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	if c.format != "json" && c.format != "table" {
+		fmt.Fprintf(stderr, "getting: unknown format %q\n", c.format)
+		return 2
+	}
+
+	if len(args) < 2 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := c.command(args[0], args[1], args[2:]); err != nil {
+		fmt.Fprintf(stderr, "getting: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+/*
+parseFlags parses the flags of fs wherever they are in args, before or after
+the command, and returns the other arguments. Everything after "--" is an
+argument. */
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	a := []string{}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return a, nil
+		}
+
+		// Parse stops at the first argument, or consumes a "--".
+		if i := len(args) - len(rest) - 1; i >= 0 && args[i] == "--" {
+			return append(a, rest...), nil
+		}
+
+		a = append(a, rest[0])
+		args = rest[1:]
+	}
+}
+
+/*
+client creates a Getting client for bookmark b. */
+func (c *cli) client(b string) (*getting.Getting, error) {
+	opts := []getting.Option{}
+
+	if c.bearer != "" {
+		u, err := url.Parse(b)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, getting.WithAuth(u.Scheme+"://"+u.Host, auth.NewBearer(c.bearer)))
+	}
+
+	return getting.New(b, opts...)
+}
+
+/*
+command runs command n against the resource at uri u. */
+func (c *cli) command(n string, u string, args []string) error {
+	g, err := c.client(u)
+	if err != nil {
+		return err
+	}
+
+	r, err := g.Go("")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch n {
+	case "shell":
+		return c.shell(g, r)
+	case "get":
+		return c.get(ctx, r)
+	case "links":
+		return c.links(ctx, r, optional(args))
+	case "follow":
+		if len(args) < 1 {
+			return errors.New("follow needs a rel")
+		}
+
+		v, err := variables(args[1:])
+		if err != nil {
+			return err
+		}
+
+		t, err := r.FollowContext(ctx, args[0], v)
+		if err != nil {
+			return err
+		}
+
+		return c.get(ctx, t)
+	case "embedded":
+		return c.embedded(ctx, r, optional(args))
+	case "graph":
+		return c.graph(ctx, g, optional(args))
+	case "check":
+		return c.check(ctx, g, optional(args))
+	case "put", "post":
+		if len(args) != 1 {
+			return fmt.Errorf("%s needs a file", n)
+		}
+
+		b, err := c.read(args[0])
+		if err != nil {
+			return err
+		}
+
+		if n == "put" {
+			return r.Put(ctx, c.contentType, b)
+		}
+
+		t, err := r.Post(ctx, c.contentType, b)
+		if err != nil {
+			return err
+		}
+
+		if t != nil {
+			fmt.Fprintln(c.stdout, t.Location().String())
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", n)
+	}
+}
+
+/*
+graph crawls the API from the bookmark of g and prints the link graph in
+format f: dot, mermaid or json. */
+func (c *cli) graph(ctx context.Context, g *getting.Getting, f string) error {
+	gr, err := crawl.New(g, crawl.MaxDepth(c.depth)).Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case "", "dot":
+		return gr.WriteDOT(c.stdout)
+	case "mermaid":
+		return gr.WriteMermaid(c.stdout)
+	case "json":
+		return gr.WriteJSON(c.stdout)
+	default:
+		return fmt.Errorf("unknown graph format %q", f)
+	}
+}
+
+/*
+check checks every link reachable from the bookmark of g and prints the
+report in format f: text, json or junit. It fails when a link is broken. */
+func (c *cli) check(ctx context.Context, g *getting.Getting, f string) error {
+	r, err := linkcheck.Check(ctx, g, linkcheck.WithCrawlOptions(crawl.MaxDepth(c.depth)))
+	if err != nil {
+		return err
+	}
+
+	switch f {
+	case "", "text":
+		for _, p := range r.Problems {
+			fmt.Fprintf(c.stdout, "%s\t%s\t%s\t%s\n", p.Kind, p.From, p.Rel, p.Message)
+		}
+	case "json":
+		err = r.WriteJSON(c.stdout)
+	case "junit":
+		err = r.WriteJUnit(c.stdout)
+	default:
+		return fmt.Errorf("unknown check format %q", f)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !r.OK() {
+		return fmt.Errorf("%d of %d links broken", len(r.Problems), r.Checked)
+	}
+
+	return nil
+}
+
+/*
+read returns the contents of file f, or of stdin when f is "-". */
+func (c *cli) read(f string) ([]byte, error) {
+	if f == "-" {
+		return ioutil.ReadAll(c.stdin)
+	}
+
+	return ioutil.ReadFile(f)
+}
+
+/*
+optional returns the first argument, or "" when there is none. */
+func optional(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	return args[0]
+}
+
+/*
+variables parses var=value arguments into template variables. */
+func variables(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	v := map[string]string{}
+	for _, a := range args {
+		kv := strings.SplitN(a, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected var=value", a)
+		}
+
+		v[kv[0]] = kv[1]
+	}
+
+	return v, nil
+}
+
+/*
+representation fetches the representation of r. */
+func representation(ctx context.Context, r *resource.Resource) (resource.Representor, error) {
+	return r.Representation(ctx)
+}
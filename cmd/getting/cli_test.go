@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Run(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/hal+json")
+
+		switch {
+		case r.Method == "POST":
+			b, _ := ioutil.ReadAll(r.Body)
+			if string(b) != `{"title": "new"}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Location", "/articles/2")
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/":
+			w.Write([]byte(`{
+				"_links": {"self": {"href": "/", "title": "Home"}, "articles": {"href": "/articles", "title": "Articles"}, "search": {"href": "/articles{?q}", "title": "Search", "templated": true}},
+				"_embedded": {"articles": {"_links": {"self": {"href": "/articles", "title": "Articles"}}}},
+				"name": "home"
+			}`))
+		default:
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.String() + `", "title": "Found"}}, "query": "` + r.URL.Query().Get("q") + `"}`))
+		}
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		code  int
+		want  []string
+	}{
+		{
+			"get table",
+			[]string{"get", s.URL},
+			"",
+			0,
+			[]string{`name`, `"home"`, "articles", s.URL + "/articles"},
+		},
+		{
+			"links json",
+			[]string{"-format", "json", "links", s.URL, "articles"},
+			"",
+			0,
+			[]string{`"rel": "articles"`, `"href": "` + s.URL + `/articles"`},
+		},
+		{
+			"follow templated",
+			[]string{"-format", "json", "follow", s.URL, "search", "q=hal"},
+			"",
+			0,
+			[]string{`"query": "hal"`},
+		},
+		{
+			"flags after the command",
+			[]string{"links", "-format", "json", s.URL, "-depth", "1", "articles"},
+			"",
+			0,
+			[]string{`"rel": "articles"`},
+		},
+		{
+			"embedded",
+			[]string{"embedded", s.URL},
+			"",
+			0,
+			[]string{"articles", s.URL + "/articles", "Articles"},
+		},
+		{
+			"post stdin",
+			[]string{"post", s.URL, "-"},
+			`{"title": "new"}`,
+			0,
+			[]string{s.URL + "/articles/2"},
+		},
+		{
+			"graph mermaid",
+			[]string{"-depth", "1", "graph", s.URL, "mermaid"},
+			"",
+			0,
+			[]string{"flowchart LR", `-.->|"search"|`},
+		},
+		{
+			"check junit",
+			[]string{"-depth", "1", "check", s.URL, "junit"},
+			"",
+			0,
+			[]string{"<testsuite", `failures="0"`},
+		},
+		{
+			"error unknown command",
+			[]string{"delete", s.URL},
+			"",
+			1,
+			nil,
+		},
+		{
+			"error unknown flag after the command",
+			[]string{"get", s.URL, "-H", "Accept: text/html"},
+			"",
+			2,
+			nil,
+		},
+		{
+			"error usage",
+			[]string{"get"},
+			"",
+			2,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			args := append([]string{"-bearer", "secret"}, tt.args...)
+			if code := run(args, strings.NewReader(tt.stdin), stdout, stderr); code != tt.code {
+				t.Errorf("run() expected exit code %d, got %d: %s", tt.code, code, stderr.String())
+			}
+
+			for _, w := range tt.want {
+				if !strings.Contains(stdout.String(), w) {
+					t.Errorf("run() output should contain %q, got:\n%s", w, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_Shell(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"_links": {"self": {"href": "/", "title": "Home"}, "articles": {"href": "/articles", "title": "Articles"}, "author": {"href": "/people/1", "title": "Author"}, "search": {"href": "/search{?q}", "title": "Search", "templated": true}}}`))
+		default:
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.String() + `", "title": "Found"}}, "query": "` + r.URL.Query().Get("q") + `"}`))
+		}
+	}))
+	defer s.Close()
+
+	session := strings.Join([]string{
+		"follow articles",
+		"history",
+		"back",
+		"follow search",
+		"hal",
+		"back",
+		"follow missing",
+		"exit",
+	}, "\n")
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	if code := run([]string{"shell", s.URL}, strings.NewReader(session), stdout, stderr); code != 0 {
+		t.Fatalf("run() expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	for _, w := range []string{
+		"  1  " + s.URL + "\n  2  " + s.URL + "/articles",
+		"q: ",
+		`"hal"`,
+	} {
+		if !strings.Contains(stdout.String(), w) {
+			t.Errorf("shell output should contain %q, got:\n%s", w, stdout.String())
+		}
+	}
+
+	if !strings.Contains(stderr.String(), "link not found") {
+		t.Errorf("shell should report errors, got %q", stderr.String())
+	}
+}
+
+func Test_Shell_Complete(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(`{"_links": {"self": {"href": "/", "title": "Home"}, "articles": {"href": "/articles", "title": "Articles"}, "author": {"href": "/people/1", "title": "Author"}}}`))
+	}))
+	defer s.Close()
+
+	c := cli{}
+	g, _ := c.client(s.URL)
+	r, _ := g.Go("")
+	sh := shell{c: &c, g: g, current: r}
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"fo", []string{"follow"}},
+		{"follow a", []string{"articles", "author"}},
+		{"follow ar", []string{"articles"}},
+		{"links ", []string{"articles", "author", "self"}},
+		{"follow articles ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got := sh.complete(tt.line)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("shell.complete(%q) expected %v, got %v", tt.line, tt.want, got)
+			}
+		})
+	}
+}
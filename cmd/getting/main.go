@@ -0,0 +1,33 @@
+/*
+Command getting is a hypermedia browser for the terminal. It fetches a
+resource, lists its links and embedded resources, follows relations and sends
+PUT and POST requests, all starting from a single uri.
+
+Usage:
+
+	getting [flags] <command> <uri> [arguments]
+
+Flags may also follow the command, like getting get -format json <uri>.
+Arguments after "--" are never read as flags.
+
+Commands:
+
+	get <uri>                           print the representation
+	links <uri> [rel]                   list the links, optionally of one rel
+	follow <uri> <rel> [var=value...]   follow a relation and print the target
+	embedded <uri> [rel]                list the embedded resources
+	put <uri> <file>                    replace the resource with a file ("-" is stdin)
+	post <uri> <file>                   post a file ("-" is stdin) to the resource
+	shell <uri>                         browse interactively, with tab completion of rels
+	graph <uri> [dot|mermaid|json]      crawl the API and print its link graph
+	check <uri> [text|json|junit]       crawl the API and report its broken links
+*/
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/identbase/getting/pkg/link"
+	"github.com/identbase/getting/pkg/resource"
+	"github.com/identbase/getting/pkg/resource/representor"
+)
+
+/*
+linkView is how a link is printed. */
+type linkView struct {
+	Rel       string `json:"rel"`
+	HRef      string `json:"href"`
+	Title     string `json:"title,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+/*
+embeddedView is how an embedded resource is printed. */
+type embeddedView struct {
+	Rel   string `json:"rel"`
+	URI   string `json:"uri"`
+	Title string `json:"title,omitempty"`
+}
+
+/*
+get prints the representation of r. */
+func (c *cli) get(ctx context.Context, r *resource.Resource) error {
+	o, err := r.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if c.format == "json" {
+		return c.json(o)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "URI\t%s\n", r.Location().String())
+
+	if h, ok := o.(representor.HALBody); ok {
+		keys := make([]string, 0, len(h.Properties))
+		for k := range h.Properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			b, err := json.Marshal(h.Properties[k])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(w, "%s\t%s\n", k, b)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.stdout)
+
+	return c.links(ctx, r, "")
+}
+
+/*
+links prints the links of r with reltype rt, or all links when rt is "". */
+func (c *cli) links(ctx context.Context, r *resource.Resource, rt string) error {
+	repr, err := representation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	l := repr.GetLinks(rt)
+	sort.SliceStable(l, func(i, j int) bool {
+		return l[i].Rel < l[j].Rel
+	})
+
+	v := make([]linkView, 0, len(l))
+	for _, k := range l {
+		v = append(v, newLinkView(k))
+	}
+
+	if c.format == "json" {
+		return c.json(v)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REL\tHREF\tTITLE\tTYPE")
+	for _, k := range v {
+		h := k.HRef
+		if k.Templated {
+			h += " (templated)"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.Rel, h, k.Title, k.Type)
+	}
+
+	return w.Flush()
+}
+
+/*
+embedded prints the embedded resources of r with reltype rt, or all of them
+when rt is "". */
+func (c *cli) embedded(ctx context.Context, r *resource.Resource, rt string) error {
+	repr, err := representation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	e := map[string][]representor.Representor{}
+	if h, ok := repr.(*representor.HALRepresentor); ok && rt == "" {
+		e = h.Embedded
+	} else {
+		e[rt] = repr.GetEmbedded(rt)
+	}
+
+	rels := make([]string, 0, len(e))
+	for k := range e {
+		rels = append(rels, k)
+	}
+	sort.Strings(rels)
+
+	v := []embeddedView{}
+	for _, k := range rels {
+		for _, er := range e[k] {
+			u := er.GetURI()
+			ev := embeddedView{
+				Rel: k,
+				URI: u.String(),
+			}
+
+			if l, err := er.GetLink("self"); err == nil {
+				ev.Title = l.Title
+			}
+
+			v = append(v, ev)
+		}
+	}
+
+	if c.format == "json" {
+		return c.json(v)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REL\tURI\tTITLE")
+	for _, k := range v {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", k.Rel, k.URI, k.Title)
+	}
+
+	return w.Flush()
+}
+
+/*
+json prints o as indented JSON. */
+func (c *cli) json(o interface{}) error {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.stdout, strings.TrimSpace(string(b)))
+
+	return err
+}
+
+/*
+newLinkView creates the printed form of l. */
+func newLinkView(l link.Link) linkView {
+	v := linkView{
+		Rel:       l.Rel,
+		HRef:      l.HRef,
+		Title:     l.Title,
+		Type:      l.Type,
+		Name:      l.Name,
+		Templated: l.Templated,
+	}
+
+	if !l.Templated {
+		if h, err := l.Resolve(); err == nil {
+			v.HRef = h
+		}
+	}
+
+	return v
+}
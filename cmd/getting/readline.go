@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+lineReader reads the lines typed in the shell. */
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+/*
+completer returns the candidates for the last word of line. */
+type completer func(line string) []string
+
+/*
+newLineReader returns a lineReader with tab completion when in is a terminal,
+and one reading plain lines otherwise. */
+func newLineReader(in io.Reader, out io.Writer, c completer) lineReader {
+	if f, ok := in.(*os.File); ok && isTerminal(f.Fd()) {
+		e := editor{
+			fd:       f.Fd(),
+			in:       bufio.NewReader(f),
+			out:      out,
+			complete: c,
+		}
+
+		return &e
+	}
+
+	p := plainReader{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+
+	return &p
+}
+
+/*
+plainReader reads lines without any editing support. */
+type plainReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+/*
+ReadLine prints the prompt and reads a line. */
+func (p *plainReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+
+	l, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || l == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(l, "\r\n"), nil
+}
+
+/*
+editor is a minimal line editor for a terminal in raw mode, supporting
+backspace, Ctrl-C, Ctrl-D and tab completion. */
+type editor struct {
+	fd       uintptr
+	in       *bufio.Reader
+	out      io.Writer
+	complete completer
+}
+
+/*
+ReadLine prints the prompt and reads a line, completing the last word on tab. */
+func (e *editor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	buf := []rune{}
+	redraw := func() {
+		fmt.Fprint(e.out, "\r\x1b[K"+prompt+string(buf))
+	}
+
+	fmt.Fprint(e.out, prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(buf), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			buf = buf[:0]
+			fmt.Fprint(e.out, prompt)
+		case 4: // Ctrl-D
+			if len(buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+		case 8, 127: // Backspace
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				fmt.Fprint(e.out, "\b \b")
+			}
+		case '\t':
+			line := string(buf)
+			c := e.complete(line)
+			if len(c) == 0 {
+				continue
+			}
+
+			word := line[strings.LastIndex(line, " ")+1:]
+			if len(c) == 1 {
+				buf = []rune(line[:len(line)-len(word)] + c[0] + " ")
+				redraw()
+				continue
+			}
+
+			if p := commonPrefix(c); len(p) > len(word) {
+				buf = []rune(line[:len(line)-len(word)] + p)
+				redraw()
+				continue
+			}
+
+			fmt.Fprint(e.out, "\r\n"+strings.Join(c, "  ")+"\r\n")
+			redraw()
+		case 27: // Escape sequences, like the arrow keys, are ignored.
+			if b, err := e.in.ReadByte(); err == nil && b == '[' {
+				for {
+					b, err := e.in.ReadByte()
+					if err != nil || (b >= 0x40 && b <= 0x7e) {
+						break
+					}
+				}
+			}
+		default:
+			if r >= 32 {
+				buf = append(buf, r)
+				fmt.Fprint(e.out, string(r))
+			}
+		}
+	}
+}
+
+/*
+commonPrefix returns the longest prefix shared by every string of s. */
+func commonPrefix(s []string) string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	p := s[0]
+	for _, v := range s[1:] {
+		for !strings.HasPrefix(v, p) {
+			p = p[:len(p)-1]
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/identbase/getting"
+	"github.com/identbase/getting/pkg/resource"
+)
+
+// shellCommands are the commands understood by the shell.
+var shellCommands = []string{"back", "embedded", "exit", "follow", "get", "go", "help", "history", "links"}
+
+/*
+shell is an interactive session browsing an API, it keeps the current resource
+and the resources visited to get there. */
+type shell struct {
+	c       *cli
+	g       *getting.Getting
+	in      lineReader
+	current *resource.Resource
+	history []*resource.Resource
+}
+
+/*
+shell starts an interactive session at the resource r. */
+func (c *cli) shell(g *getting.Getting, r *resource.Resource) error {
+	s := shell{
+		c:       c,
+		g:       g,
+		current: r,
+	}
+	s.in = newLineReader(c.stdin, c.stdout, s.complete)
+
+	ctx := context.Background()
+	fmt.Fprintln(c.stdout, `Type "help" for a list of commands.`)
+
+	for {
+		l, err := s.in.ReadLine(s.current.Location().String() + "> ")
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		f := strings.Fields(l)
+		if len(f) == 0 {
+			continue
+		}
+
+		if f[0] == "exit" || f[0] == "quit" {
+			return nil
+		}
+
+		if err := s.exec(ctx, f[0], f[1:]); err != nil {
+			fmt.Fprintf(c.stderr, "error: %v\n", err)
+		}
+	}
+}
+
+/*
+exec runs one shell command. */
+func (s *shell) exec(ctx context.Context, n string, args []string) error {
+	switch n {
+	case "get":
+		return s.c.get(ctx, s.current)
+	case "links":
+		return s.c.links(ctx, s.current, optional(args))
+	case "embedded":
+		return s.c.embedded(ctx, s.current, optional(args))
+	case "follow":
+		if len(args) < 1 {
+			return errors.New("follow needs a rel")
+		}
+
+		return s.follow(ctx, args[0], args[1:])
+	case "go":
+		if len(args) != 1 {
+			return errors.New("go needs a uri")
+		}
+
+		r, err := s.current.Go(args[0])
+		if err != nil {
+			return err
+		}
+
+		s.visit(r)
+
+		return nil
+	case "back":
+		if len(s.history) == 0 {
+			return errors.New("no previous resource")
+		}
+
+		s.current = s.history[len(s.history)-1]
+		s.history = s.history[:len(s.history)-1]
+
+		return nil
+	case "history":
+		for i, r := range s.history {
+			fmt.Fprintf(s.c.stdout, "%3d  %s\n", i+1, r.Location().String())
+		}
+		fmt.Fprintf(s.c.stdout, "%3d  %s\n", len(s.history)+1, s.current.Location().String())
+
+		return nil
+	case "help":
+		fmt.Fprintln(s.c.stdout, `get                        print the current resource
+links [rel]                list the links
+embedded [rel]             list the embedded resources
+follow <rel> [var=value]   follow a relation, asking for missing template variables
+go <uri>                   go to a uri, relative to the current resource
+back                       return to the previous resource
+history                    list the resources visited
+exit                       leave the shell`)
+
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", n)
+	}
+}
+
+/*
+follow follows the relation rt of the current resource. When the link is
+templated, the variables that are not in args are asked for. */
+func (s *shell) follow(ctx context.Context, rt string, args []string) error {
+	v, err := variables(args)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.current.GetContext(ctx); err != nil {
+		return err
+	}
+
+	l, err := s.current.LinkContext(ctx, rt)
+	if err != nil {
+		return err
+	}
+
+	names, err := l.Variables()
+	if err != nil {
+		return err
+	}
+
+	for _, n := range names {
+		if _, ok := v[n]; ok {
+			continue
+		}
+
+		a, err := s.in.ReadLine(n + ": ")
+		if err != nil {
+			return err
+		}
+
+		if v == nil {
+			v = map[string]string{}
+		}
+		v[n] = a
+	}
+
+	r, err := s.current.FollowContext(ctx, rt, v)
+	if err != nil {
+		return err
+	}
+
+	s.visit(r)
+
+	return s.c.get(ctx, r)
+}
+
+/*
+visit makes r the current resource. */
+func (s *shell) visit(r *resource.Resource) {
+	s.history = append(s.history, s.current)
+	s.current = r
+}
+
+/*
+complete returns the candidates for the last word of line: command names for
+the first word, and the rels of the current resource for follow, links and
+embedded. */
+func (s *shell) complete(line string) []string {
+	f := strings.Fields(line)
+	word := line[strings.LastIndex(line, " ")+1:]
+
+	var cands []string
+	switch {
+	case len(f) == 0 || (len(f) == 1 && word != ""):
+		cands = shellCommands
+	case (len(f) == 1 || (len(f) == 2 && word != "")) && (f[0] == "follow" || f[0] == "links" || f[0] == "embedded"):
+		cands = s.rels(context.Background())
+	default:
+		return nil
+	}
+
+	m := []string{}
+	for _, c := range cands {
+		if strings.HasPrefix(c, word) {
+			m = append(m, c)
+		}
+	}
+
+	return m
+}
+
+/*
+rels returns the distinct rels of the links of the current resource, it is
+empty when the resource cannot be fetched. */
+func (s *shell) rels(ctx context.Context) []string {
+	repr, err := representation(ctx, s.current)
+	if err != nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	rels := []string{}
+	for _, l := range repr.GetLinks("") {
+		if !seen[l.Rel] {
+			seen[l.Rel] = true
+			rels = append(rels, l.Rel)
+		}
+	}
+	sort.Strings(rels)
+
+	return rels
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"errors"
+)
+
+/*
+isTerminal reports whether fd is a terminal. Raw mode is only implemented on
+Linux, elsewhere the shell reads plain lines without tab completion. */
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+/*
+makeRaw is not supported on this platform. */
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal mode unsupported")
+}
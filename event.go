@@ -0,0 +1,73 @@
+package getting
+
+import (
+	"net/http"
+	"time"
+)
+
+/*
+EventType identifies what happened in an Event. */
+type EventType int
+
+const (
+	// EventRetry is emitted before a failed request is sent again.
+	EventRetry EventType = iota
+	// EventCacheHit is emitted when a response is served from the cache.
+	EventCacheHit
+	// EventCacheRevalidated is emitted when the server confirmed a stale
+	// cache entry is still valid.
+	EventCacheRevalidated
+	// EventInvalidated is emitted when a resource is invalidated.
+	EventInvalidated
+	// EventRedirected is emitted when a permanent redirect moved a
+	// resource, URI is where it moved to.
+	EventRedirected
+	// EventReconnecting is emitted before a broken notification connection
+	// is opened again, Err is why it broke.
+	EventReconnecting
+)
+
+/*
+String returns the name of the event type. */
+func (t EventType) String() string {
+	switch t {
+	case EventRetry:
+		return "retry"
+	case EventCacheHit:
+		return "cache hit"
+	case EventCacheRevalidated:
+		return "cache revalidated"
+	case EventInvalidated:
+		return "invalidated"
+	case EventRedirected:
+		return "redirected"
+	case EventReconnecting:
+		return "reconnecting"
+	default:
+		return "unknown"
+	}
+}
+
+/*
+Event describes something the client did while talking to a server. Not every
+field is set for every EventType. */
+type Event struct {
+	Type     EventType
+	Request  *http.Request
+	Response *http.Response
+	Err      error
+	// URI is the resource the event is about, when there is no request.
+	URI string
+	// Attempt is the number of the attempt that is about to be made.
+	Attempt int
+	// Delay is how long the client waits before the next attempt.
+	Delay time.Duration
+}
+
+/*
+emit calls every registered hook with e. */
+func (g *Getting) emit(e Event) {
+	for _, h := range g.hooks {
+		h(e)
+	}
+}
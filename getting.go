@@ -2,8 +2,15 @@ package getting
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 
+	"github.com/identbase/getting/pkg/auth"
+	"github.com/identbase/getting/pkg/cache"
+	"github.com/identbase/getting/pkg/ratelimit"
 	"github.com/identbase/getting/pkg/resource"
 )
 
@@ -13,50 +20,349 @@ Getting. */
 type Getting struct {
 	// Bookmark is the default uri to use on all requests.
 	bookmark string
+	// client performs the actual HTTP requests.
+	client *http.Client
+	// auth holds the authentication providers, keyed by origin.
+	auth map[string]auth.Provider
+	// retryPolicy decides if and when failed requests are sent again.
+	retryPolicy RetryPolicy
+	// hooks are called for every Event the client emits.
+	hooks []func(Event)
+	// limits holds the rate limiters, keyed by host.
+	limits map[string]*ratelimit.Limiter
+	// cache stores responses, it is nil when caching is disabled.
+	cache cache.Cache
+	// redirectPolicy decides what permanent redirects change.
+	redirectPolicy RedirectPolicy
+
+	// err is the first error of the options, returned by New.
+	err error
+
+	mu sync.Mutex
+	// resources holds the resources handed out, keyed by uri.
+	resources *resources
 }
 
 /*
-New creates a new Getting object. */
-func New(b string) (*Getting, error) {
+Option configures a Getting object. */
+type Option func(*Getting)
+
+/*
+New creates a new Getting object. It fails when an option is invalid. */
+func New(b string, opts ...Option) (*Getting, error) {
 	if b == "" {
 		return nil, errors.New("bookmark unspecified")
 	}
 
 	g := &Getting{
-		bookmark: b,
+		bookmark:  b,
+		client:    &http.Client{},
+		auth:      map[string]auth.Provider{},
+		limits:    map[string]*ratelimit.Limiter{},
+		resources: newResources(DefaultResourceLimit),
+		retryPolicy: RetryPolicy{
+			MaxAttempts: 1,
+		},
+	}
+
+	for _, o := range opts {
+		o(g)
+	}
+
+	if g.err != nil {
+		return nil, g.err
 	}
 
+	// Credentials must not follow a redirect to another origin, the client
+	// is copied so the one given to WithHTTPClient is left alone.
+	c := *g.client
+	c.CheckRedirect = g.checkRedirect(c.CheckRedirect)
+	g.client = &c
+
 	return g, nil
 }
 
+/*
+WithHTTPClient sets the http.Client used to perform requests. */
+func WithHTTPClient(c *http.Client) Option {
+	return func(g *Getting) {
+		g.client = c
+	}
+}
+
+/*
+WithTransport sets the http.RoundTripper of the http.Client used to perform
+requests, to record or replay traffic for example. */
+func WithTransport(rt http.RoundTripper) Option {
+	return func(g *Getting) {
+		c := *g.client
+		c.Transport = rt
+		g.client = &c
+	}
+}
+
+/*
+WithAuth attaches an authentication provider to every request sent to the
+origin o, for example "https://api.example.org". New fails when o is not an
+absolute uri. */
+func WithAuth(o string, p auth.Provider) Option {
+	return func(g *Getting) {
+		u, err := url.Parse(o)
+		if err == nil && u.Host == "" {
+			err = fmt.Errorf("auth origin %q has no host", o)
+		}
+		if err != nil {
+			if g.err == nil {
+				g.err = fmt.Errorf("invalid auth origin: %w", err)
+			}
+			return
+		}
+
+		g.auth[origin(u)] = p
+	}
+}
+
+/*
+WithRateLimit attaches a rate limiter to every request sent to host h, for
+example "api.example.org" or "localhost:8000". */
+func WithRateLimit(h string, l *ratelimit.Limiter) Option {
+	return func(g *Getting) {
+		g.limits[strings.ToLower(h)] = l
+	}
+}
+
+/*
+WithEventHook registers a function that is called for every Event the client
+emits. */
+func WithEventHook(h func(Event)) Option {
+	return func(g *Getting) {
+		g.hooks = append(g.hooks, h)
+	}
+}
+
+/*
+origin returns the scheme and host of a uri. */
+func origin(u *url.URL) string {
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+/*
+checkRedirect returns the CheckRedirect function of the http.Client, which
+removes the API key header of the origin the request started at when a
+redirect leaves it, like net/http does for the Authorization header, and then
+calls f. */
+func (g *Getting) checkRedirect(f func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		from := origin(via[0].URL)
+		if origin(req.URL) != from {
+			if k, ok := g.auth[from].(*auth.APIKey); ok && k.In == auth.InHeader {
+				req.Header.Del(k.Name)
+			}
+		}
+
+		if f != nil {
+			return f(req, via)
+		}
+
+		// The default policy of http.Client.
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		return nil
+	}
+}
+
 /*
 Follow is a shortcut for Go. */
-func (g *Getting) Follow(rt string, v map[string]string) (*resource.Resource, error) {
+func (g *Getting) Follow(rt string, v map[string]string, opts ...resource.FollowOption) (*resource.Resource, error) {
 	r, err := g.Go("")
 	if err != nil {
 		return nil, err
 	}
 
-	return r.Follow(rt, v)
+	return r.Follow(rt, v, opts...)
 
 }
 
+/*
+Chain returns a FollowChain starting at the bookmark, for example
+g.Chain().Follow("articles").Follow("author").Get(ctx). */
+func (g *Getting) Chain() *resource.FollowChain {
+	return resource.NewFollowChain(g, "")
+}
+
 /*
 Go returns a resource by its uri. This function doesnt require a uri
 if one is not specified, it will return the bookmark resource. */
 func (g *Getting) Go(u string) (*resource.Resource, error) {
+	h, err := g.resolve(u)
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := url.Parse(h)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if r, ok := g.resources.get(uri.String()); ok {
+		return r, nil
+	}
+
+	r := resource.New(g, uri)
+	g.resources.add(uri.String(), r)
+
+	return r, nil
+}
+
+/*
+resolve returns uri u resolved against the bookmark. */
+func (g *Getting) resolve(u string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, err := url.Parse(g.bookmark)
+	if err != nil {
+		return "", err
+	}
 
-	ubuf, err := url.Parse(g.bookmark)
+	r, err := b.Parse(u)
 	if err != nil {
+		return "", err
+	}
+
+	return r.String(), nil
+}
+
+/*
+Do sends an HTTP request on behalf of a resource, retrying it according to the
+client's RetryPolicy. GET requests are served from the cache when possible,
+unless they carry Cache-Control: no-store. Permanent redirects are handled
+according to the RedirectPolicy, and the responses to unsafe methods
+invalidate the resources they changed. */
+func (g *Getting) Do(req *http.Request) (*http.Response, error) {
+	if g.cache != nil && req.Method == "GET" && !cache.NoStore(req.Header) {
+		resp, err := g.cached(req)
+		if err != nil {
+			return nil, err
+		}
+
+		g.redirected(req, resp)
+		return resp, nil
+	}
+
+	resp, err := g.retry(req)
+	if err != nil {
+		return nil, err
+	}
+
+	g.redirected(req, resp)
+	g.invalidateFor(req, resp)
+
+	return resp, nil
+}
+
+/*
+send performs a single HTTP exchange. The authentication provider registered
+for the request's origin, if any, is applied first, to a copy of the request:
+the request is sent again on retries and revalidations, and its uri names the
+resource, so it never carries the credentials. */
+func (g *Getting) send(req *http.Request) (*http.Response, error) {
+	p, ok := g.auth[origin(req.URL)]
+	if !ok {
+		return g.exchange(req)
+	}
+
+	a := req.Clone(req.Context())
+	if err := p.Authenticate(a); err != nil {
+		return nil, err
+	}
+
+	resp, err := g.exchange(a)
+	if err != nil {
+		return nil, err
+	}
+	unauthenticated(resp, a, req)
+
+	rp, ok := p.(auth.Refresher)
+	if !ok || resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	// The credentials were rejected, refresh them and try exactly once more
+	// if the request body can be sent again.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	if err := rp.Refresh(); err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.Authenticate(retry); err != nil {
+		return nil, err
+	}
+
+	resp, err = g.exchange(retry)
+	if err != nil {
+		return nil, err
+	}
+	unauthenticated(resp, retry, req)
+
+	return resp, nil
+}
+
+/*
+unauthenticated replaces authenticated request a with request req in the
+redirect chain of response resp, so that the uri of the response, and of the
+cache entry made from it, are free of credentials. */
+func unauthenticated(resp *http.Response, a *http.Request, req *http.Request) {
+	if resp.Request == a {
+		resp.Request = req
+		return
+	}
+
+	for r := resp.Request; r != nil && r.Response != nil; r = r.Response.Request {
+		if r.Response.Request == a {
+			r.Response.Request = req
+			return
+		}
+	}
+}
+
+/*
+exchange waits for the rate limiter of the request's host, if any, and then
+sends the request. */
+func (g *Getting) exchange(req *http.Request) (*http.Response, error) {
+	l, ok := g.limits[strings.ToLower(req.URL.Host)]
+	if !ok {
+		return g.client.Do(req)
+	}
+
+	if err := l.Wait(req.Context()); err != nil {
 		return nil, err
 	}
 
-	uri, err := ubuf.Parse(u)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: Use some sort of cache system to prevent rerequesting things
+	l.Update(resp.Header)
 
-	return resource.New(g, uri), nil
+	return resp, nil
 }
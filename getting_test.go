@@ -1,17 +1,28 @@
 package getting
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	// "io"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/identbase/getting/pkg/auth"
+	"github.com/identbase/getting/pkg/cache"
+	"github.com/identbase/getting/pkg/ratelimit"
 	"github.com/identbase/getting/pkg/resource"
 	"github.com/identbase/getting/pkg/resource/representor"
+	"github.com/identbase/getting/pkg/websocket"
 )
 
 // Unit tests
@@ -131,7 +142,7 @@ func Test_Getting_SimpleResourceGet(t *testing.T) {
 				Properties: map[string]interface{}{
 					"foo": "bar",
 				},
-				Embedded: map[string]representor.HALBody{},
+				Embedded: map[string][]representor.HALBody{},
 			},
 			nil,
 		},
@@ -192,3 +203,1386 @@ func Test_Getting_SimpleResourceGet(t *testing.T) {
 	}
 
 }
+
+func Test_Getting_AuthRefreshOnUnauthorized(t *testing.T) {
+	issued := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", issued),
+			"token_type":   "bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer ts.Close()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the second token issued is accepted.
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(`{"_links": {"self": {"href": "/", "title": "Test"}}}`))
+	}))
+	defer s.Close()
+
+	g, err := New(s.URL, WithAuth(s.URL, auth.NewClientCredentials(ts.URL, "client", "secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := g.Go("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Get(); err != nil {
+		t.Errorf("Resource.Get() should not error, got %v", err)
+	}
+
+	if issued != 2 {
+		t.Errorf("Getting.Do() should refresh the token once, issued %d", issued)
+	}
+}
+
+func Test_Getting_AuthRedirect(t *testing.T) {
+	keys := map[string]string{}
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys["other"] = r.Header.Get("X-API-Key")
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(`{"_links": {"self": {"href": "/"}}}`))
+	}))
+	defer other.Close()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys[r.URL.Path] = r.Header.Get("X-API-Key")
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/target", http.StatusFound)
+		case "/cross":
+			http.Redirect(w, r, other.URL+"/", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_links": {"self": {"href": "/target"}}}`))
+		}
+	}))
+	defer s.Close()
+
+	g, err := New(s.URL, WithAuth(s.URL, auth.NewAPIKeyHeader("X-API-Key", "secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"/same", "/cross"} {
+		r, _ := g.Go(p)
+		if _, err := r.Get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if keys["/target"] != "secret" {
+		t.Errorf("Getting.Do() should keep the api key on a same origin redirect, got %q", keys["/target"])
+	}
+
+	if keys["other"] != "" {
+		t.Errorf("Getting.Do() should remove the api key on a cross origin redirect, got %q", keys["other"])
+	}
+
+	if _, err := New(s.URL, WithAuth("api.example.org", auth.NewBearer("token"))); err == nil {
+		t.Errorf("getting.New() should error on an invalid auth origin")
+	}
+}
+
+func Test_Getting_AuthQueryRetryCache(t *testing.T) {
+	queries := []string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if len(queries) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Header().Set("Cache-Control", "max-age=3600")
+		w.Write([]byte(`{"_links": {"self": {"href": "/articles"}}}`))
+	}))
+	defer s.Close()
+
+	hits := 0
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	g, err := New(s.URL, WithAuth(s.URL, auth.NewAPIKeyQuery("k", "secret")), WithRetry(p), WithCache(cache.NewMemory(10)), WithEventHook(func(e Event) {
+		if e.Type == EventCacheHit {
+			hits++
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := g.Go("/articles")
+	if _, err := r.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 2 || queries[0] != "k=secret" || queries[1] != "k=secret" {
+		t.Errorf("Getting.Do() should send the key once per request, got %q", queries)
+	}
+
+	if u := r.State().URI.String(); u != s.URL+"/articles" {
+		t.Errorf("Resource.State() should not hold the key, got %s", u)
+	}
+
+	r.Invalidate()
+	if _, err := r.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	if hits != 1 || len(queries) != 2 {
+		t.Errorf("Getting.Do() should serve the resource from the cache, got %d hits and %d requests", hits, len(queries))
+	}
+}
+
+func Test_Getting_RetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		fail     int
+		status   int
+		attempts int
+		retries  int
+		after    string
+		err      bool
+	}{
+		{
+			"success after retries",
+			2,
+			http.StatusServiceUnavailable,
+			3,
+			2,
+			"0",
+			false,
+		},
+		{
+			"error attempts exhausted",
+			3,
+			http.StatusTooManyRequests,
+			2,
+			1,
+			"0",
+			true,
+		},
+		{
+			"error not retryable",
+			1,
+			http.StatusNotFound,
+			3,
+			0,
+			"0",
+			true,
+		},
+		{
+			"error retry-after longer than max delay",
+			1,
+			http.StatusServiceUnavailable,
+			3,
+			0,
+			"86400",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tt.fail {
+					w.Header().Set("Retry-After", tt.after)
+					w.WriteHeader(tt.status)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/hal+json")
+				w.Write([]byte(`{"_links": {"self": {"href": "/", "title": "Test"}}}`))
+			}))
+			defer s.Close()
+
+			retries := 0
+			p := DefaultRetryPolicy()
+			p.MaxAttempts = tt.attempts
+			g, _ := New(s.URL, WithRetry(p), WithEventHook(func(e Event) {
+				if e.Type == EventRetry {
+					retries++
+				}
+			}))
+
+			r, _ := g.Go("")
+			_, err := r.Get()
+			if tt.err && err == nil {
+				t.Errorf("Resource.Get() should error, got nil")
+			} else if !tt.err && err != nil {
+				t.Errorf("Resource.Get() errored with %v when it shouldnt have", err)
+			}
+
+			if retries != tt.retries {
+				t.Errorf("Getting.Do() expected %d retries, got %d", tt.retries, retries)
+			}
+		})
+	}
+}
+
+func Test_Getting_DiskCacheOffline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "getting-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Header().Set("Cache-Control", "max-age=3600")
+		w.Write([]byte(`{"_links": {"self": {"href": "/", "title": "Test"}}, "foo": "bar"}`))
+	}))
+	u := s.URL
+
+	c, _ := cache.NewDisk(dir)
+	g, _ := New(u, WithCache(c))
+	r, _ := g.Go("")
+	if _, err := r.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Close()
+
+	// A new client, sharing only the cache directory.
+	hits := 0
+	c, _ = cache.NewDisk(dir)
+	g, _ = New(u, WithCache(c), WithEventHook(func(e Event) {
+		if e.Type == EventCacheHit {
+			hits++
+		}
+	}))
+	r, _ = g.Go("")
+
+	o, err := r.Get()
+	if err != nil {
+		t.Fatalf("Resource.Get() should be served from the cache, got %v", err)
+	}
+
+	if o.(representor.HALBody).Properties["foo"] != "bar" {
+		t.Errorf("Resource.Get() expected foo 'bar', got %v", o.(representor.HALBody).Properties["foo"])
+	}
+
+	if hits != 1 {
+		t.Errorf("Getting.Do() expected 1 cache hit, got %d", hits)
+	}
+}
+
+func Test_Getting_InvalidateAfterWrite(t *testing.T) {
+	gets := map[string]int{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			gets[r.URL.Path]++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Header().Set("Cache-Control", "max-age=3600")
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `", "title": "Test"}}}`))
+		case "PUT":
+			w.Header().Set("Link", `</articles>; rel="invalidates"`)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer s.Close()
+
+	invalidated := 0
+	g, _ := New(s.URL, WithCache(cache.NewMemory(10)), WithEventHook(func(e Event) {
+		if e.Type == EventInvalidated {
+			invalidated++
+		}
+	}))
+
+	a, _ := g.Go("/articles")
+	b, _ := g.Go("/articles/1")
+	a.Get()
+	b.Get()
+
+	if err := b.Put(context.Background(), "application/json", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if invalidated != 2 {
+		t.Errorf("Resource.Put() expected 2 invalidations, got %d", invalidated)
+	}
+
+	a.Get()
+	b.Get()
+	a.Get()
+
+	if gets["/articles"] != 2 || gets["/articles/1"] != 2 {
+		t.Errorf("Resource.Get() should refetch invalidated resources once, got %v", gets)
+	}
+}
+
+func Test_Getting_RateLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Limit", "1")
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", "60")
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `"}, "next": {"href": "/next"}}}`))
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	g, _ := New(s.URL, WithRateLimit(u.Host, ratelimit.New(100, 10, ratelimit.Adaptive())))
+
+	r, _ := g.Go("")
+	if _, err := r.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := r.Follow("next", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server said the quota is used up for a minute.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := n.FollowContext(ctx, "next", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Resource.FollowContext() should wait for the rate limiter until the context is done, got %v", err)
+	}
+}
+
+func Test_Getting_ResourceLimit(t *testing.T) {
+	g, _ := New("http://localhost:8000", WithResourceLimit(2))
+
+	a, _ := g.Go("/a")
+	w, _ := g.Go("/watched")
+	stop := w.Watch(func(*resource.Resource) {})
+	defer stop()
+
+	g.Go("/b")
+	g.Go("/c")
+
+	if r, _ := g.Go("/watched"); r != w {
+		t.Errorf("getting.Go() should keep resources with watchers")
+	}
+
+	if r, _ := g.Go("/a"); r == a {
+		t.Errorf("getting.Go() should forget the least recently used resources")
+	}
+
+	if n := g.resources.order.Len(); n != 2 {
+		t.Errorf("getting.Go() should keep 2 resources, got %d", n)
+	}
+}
+
+func Test_Getting_PostPrepopulates(t *testing.T) {
+	gets := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			gets++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_links": {"self": {"href": "/articles/1", "title": "Fetched"}}}`))
+		case "POST":
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Header().Set("Location", "/articles/1")
+			w.Header().Set("Content-Location", "/articles/1")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"_links": {"self": {"href": "/articles/1", "title": "Created"}}}`))
+		}
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	c, _ := g.Go("/articles")
+
+	r, err := c.Post(context.Background(), "application/json", []byte(`{"title": "Created"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := r.Link("self")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Title != "Created" {
+		t.Errorf("Resource.Post() expected the created representation, got '%v'", l.Title)
+	}
+
+	if f, _ := g.Go("/articles/1"); f != r {
+		t.Errorf("Resource.Post() should store the resource in the client")
+	}
+
+	if gets != 0 {
+		t.Errorf("Resource.Post() should not need a GET, got %d", gets)
+	}
+}
+
+func Test_Getting_FollowTransclude(t *testing.T) {
+	requests := []string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/hal+json")
+
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"_links": {"self": {"href": "/", "title": "Home"}, "articles": {"href": "/articles", "title": "Articles"}}}`))
+		case "/articles":
+			if r.Header.Get("Prefer") != `transclude="item"` {
+				w.Write([]byte(`{"_links": {"self": {"href": "/articles", "title": "Articles"}, "item": [{"href": "/articles/1", "title": "One"}]}}`))
+				return
+			}
+
+			w.Write([]byte(`{
+				"_links": {"self": {"href": "/articles", "title": "Articles"}, "item": [{"href": "/articles/1", "title": "One"}]},
+				"_embedded": {"item": [{"_links": {"self": {"href": "/articles/1", "title": "One"}}, "title": "embedded"}]}
+			}`))
+		default:
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `", "title": "Fetched"}}}`))
+		}
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	a, err := g.Follow("articles", nil, resource.Transclude("item"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := a.FollowAll("item")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := items[0].Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.(representor.HALBody).Properties["title"] != "embedded" {
+		t.Errorf("Resource.Get() expected the embedded representation, got %v", o)
+	}
+
+	if len(requests) != 2 {
+		t.Errorf("Resource.FollowAll() should use embedded resources, requested %v", requests)
+	}
+}
+
+func Test_Getting_Chain(t *testing.T) {
+	requests := []string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/hal+json")
+
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"_links": {"self": {"href": "/", "title": "Home"}, "articles": {"href": "/articles", "title": "Articles"}}}`))
+		case "/articles":
+			w.Write([]byte(`{
+				"_links": {"self": {"href": "/articles", "title": "Articles"}, "new": {"href": "/articles/new", "title": "New"}},
+				"_embedded": {"new": {"_links": {"self": {"href": "/articles/new", "title": "New"}, "author": {"href": "/people/1", "title": "Author"}}}}
+			}`))
+		default:
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `", "title": "Fetched"}}, "name": "author"}`))
+		}
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		rels []string
+		hop  int
+		want int
+	}{
+		{
+			"success",
+			[]string{"articles", "new", "author"},
+			0,
+			3,
+		},
+		{
+			"error hop not found",
+			[]string{"articles", "missing", "author"},
+			2,
+			2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = []string{}
+			g, _ := New(s.URL)
+
+			c := g.Chain()
+			for _, rel := range tt.rels {
+				c = c.Follow(rel)
+			}
+
+			o, err := c.Get(context.Background())
+			if tt.hop == 0 {
+				if err != nil {
+					t.Fatalf("FollowChain.Get() errored with %v when it shouldnt have", err)
+				}
+
+				if o.(representor.HALBody).Properties["name"] != "author" {
+					t.Errorf("FollowChain.Get() expected the author, got %v", o)
+				}
+			} else {
+				var fe *resource.FollowError
+				if !errors.As(err, &fe) {
+					t.Fatalf("FollowChain.Get() should return a FollowError, got %v", err)
+				}
+
+				if fe.Hop != tt.hop || fe.Rel != tt.rels[tt.hop-1] {
+					t.Errorf("FollowChain.Get() expected hop %d, got %v", tt.hop, fe)
+				}
+			}
+
+			if len(requests) != tt.want {
+				t.Errorf("FollowChain.Get() expected %d requests, got %v", tt.want, requests)
+			}
+		})
+	}
+}
+
+func Test_Getting_ResourceState(t *testing.T) {
+	body := `{"_links": {"self": {"href": "/new", "title": "New"}}}`
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Sat, 24 Oct 2020 10:00:00 GMT")
+		w.Write([]byte(body))
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	r, _ := g.Go("/old")
+
+	if r.State() != nil {
+		t.Errorf("Resource.State() should be nil before the first request")
+	}
+
+	if _, err := r.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	st := r.State()
+	if st == nil {
+		t.Fatal("Resource.State() should not be nil after Get()")
+	}
+
+	if st.Method != "GET" || st.StatusCode != http.StatusOK || st.ETag != `"v1"` || st.LastModified != "Sat, 24 Oct 2020 10:00:00 GMT" {
+		t.Errorf("Resource.State() unexpected exchange %+v", st)
+	}
+
+	if st.URI.String() != s.URL+"/new" {
+		t.Errorf("Resource.State() expected effective uri %s/new, got %s", s.URL, st.URI)
+	}
+
+	if st.Size != int64(len(body)) || st.Fetched.IsZero() {
+		t.Errorf("Resource.State() expected %d bytes and a fetch time, got %+v", len(body), st)
+	}
+
+	st.Header.Set("ETag", "changed")
+	if r.State().Header.Get("ETag") != `"v1"` {
+		t.Errorf("Resource.State() should return a snapshot")
+	}
+}
+
+func Test_Getting_Redirects(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/v2/home", http.StatusMovedPermanently)
+		case "/temp":
+			http.Redirect(w, r, "/v2/home", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Header().Set("Cache-Control", "max-age=3600")
+			w.Write([]byte(`{"_links": {"self": {"href": "home"}, "item": {"href": "items/1"}}}`))
+		}
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		policy   RedirectPolicy
+		cache    bool
+		path     string
+		uri      string
+		bookmark string
+		hits     int
+	}{
+		{"success keep permanent", KeepURI, false, "/old", "/old", "/old", 0},
+		{"success update permanent", UpdateURI, false, "/old", "/v2/home", "/v2/home", 0},
+		{"success update temporary", UpdateURI, false, "/temp", "/temp", "/temp", 0},
+		{"success cached keep permanent", KeepURI, true, "/old", "/old", "/old", 1},
+		{"success cached update permanent", UpdateURI, true, "/old", "/v2/home", "/v2/home", 1},
+		{"success cached temporary", KeepURI, true, "/temp", "/temp", "/temp", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := 0
+			opts := []Option{WithPermanentRedirects(tt.policy), WithEventHook(func(e Event) {
+				if e.Type == EventCacheHit {
+					hits++
+				}
+			})}
+			if tt.cache {
+				opts = append(opts, WithCache(cache.NewMemory(10)))
+			}
+
+			g, _ := New(s.URL+tt.path, opts...)
+			r, _ := g.Go("")
+
+			for i := 0; i < 2; i++ {
+				// Discard the representation so the second Get goes through the cache.
+				r.Invalidate()
+
+				if _, err := r.Get(); err != nil {
+					t.Fatal(err)
+				}
+
+				l, err := r.Link("item")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if h, _ := l.Resolve(); h != s.URL+"/v2/items/1" {
+					t.Errorf("Resource.Link() should resolve against the final uri, got %s", h)
+				}
+
+				// Once the uri is updated the resource is not redirected anymore.
+				if i > 0 && tt.policy == UpdateURI && tt.uri != tt.path {
+					continue
+				}
+
+				st := r.State()
+				if len(st.Redirects) != 1 || st.Redirects[0].URI.Path != tt.path || st.Redirects[0].Location.Path != "/v2/home" {
+					t.Errorf("Resource.State() unexpected redirect chain %v", st.Redirects)
+				}
+			}
+
+			if hits != tt.hits {
+				t.Errorf("Getting.Do() expected %d cache hits, got %d", tt.hits, hits)
+			}
+
+			if l := r.Location(); l.Path != tt.uri {
+				t.Errorf("Resource.Location() expected %s, got %s", tt.uri, l.Path)
+			}
+
+			if r.URI.Path != tt.path {
+				t.Errorf("Resource.URI should not change, got %s", r.URI.Path)
+			}
+
+			if g.bookmark != s.URL+tt.bookmark {
+				t.Errorf("Getting bookmark expected %s, got %s", s.URL+tt.bookmark, g.bookmark)
+			}
+
+			for _, p := range []string{tt.path, tt.uri} {
+				if o, _ := g.Go(p); o != r {
+					t.Errorf("Getting.Go(%q) should return the redirected resource", p)
+				}
+			}
+		})
+	}
+}
+
+func Test_Getting_RedirectsConcurrent(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/v2/home", http.StatusMovedPermanently)
+		default:
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_links": {"self": {"href": "home"}, "item": {"href": "items/1"}}}`))
+		}
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL, WithPermanentRedirects(UpdateURI))
+	r, _ := g.Go("/old")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			r.Invalidate()
+			if _, err := r.Get(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if l := r.Location(); l.Path != "/v2/home" {
+		t.Errorf("Resource.Location() expected /v2/home, got %s", l.Path)
+	}
+}
+
+func Test_Getting_StreamUpload(t *testing.T) {
+	csv := "id,title\n1,One\n2,Two\n"
+	uploaded := ""
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Cache-Control", "max-age=3600")
+			w.Write([]byte(csv))
+		case "POST":
+			b, _ := ioutil.ReadAll(r.Body)
+			uploaded = r.Header.Get("Content-Type") + " " + string(b)
+			w.Header().Set("Location", "/exports/2")
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer s.Close()
+
+	hits := 0
+	g, _ := New(s.URL, WithCache(cache.NewMemory(10)), WithEventHook(func(e Event) {
+		if e.Type == EventCacheHit {
+			hits++
+		}
+	}))
+	r, _ := g.Go("/exports/1")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		st, err := r.Stream(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(st)
+		st.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b) != csv || st.ContentType != "text/csv" {
+			t.Errorf("Resource.Stream() expected the csv export, got %s %q", st.ContentType, b)
+		}
+	}
+
+	if hits != 0 {
+		t.Errorf("Resource.Stream() should not be served from the cache, got %d hits", hits)
+	}
+
+	if r.Representor != nil {
+		t.Errorf("Resource.Stream() should not set the representation")
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("id,title\n3,Three\n"))
+		pw.Close()
+	}()
+
+	n, err := r.Upload(ctx, pr, "text/csv", resource.UploadMethod("POST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uploaded != "text/csv id,title\n3,Three\n" {
+		t.Errorf("Resource.Upload() sent %q", uploaded)
+	}
+
+	if n == nil || n.URI.String() != s.URL+"/exports/2" {
+		t.Errorf("Resource.Upload() should return the Location resource, got %v", n)
+	}
+}
+
+type memoryWriterAt []byte
+
+func (m memoryWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(m[off:], p), nil
+}
+
+func Test_Getting_RangeDownload(t *testing.T) {
+	blob := make([]byte, 1000)
+	for i := range blob {
+		blob[i] = byte(i % 251)
+	}
+
+	ranges := []string{}
+	times := []time.Time{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range")+" "+r.Header.Get("If-Range"))
+		times = append(times, time.Now())
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.URL.Path == "/broken" && len(ranges) == 1 {
+			// Break the transfer after 300 bytes.
+			w.Header().Set("Content-Length", "1000")
+			w.Write(blob[:300])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		http.ServeContent(w, r, "blob.bin", time.Time{}, bytes.NewReader(blob))
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	ctx := context.Background()
+
+	t.Run("success range", func(t *testing.T) {
+		r, _ := g.Go("/blob")
+		st, err := r.Stream(ctx, resource.Range(100, 199), resource.IfRange(`"v1"`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer st.Close()
+
+		b, _ := ioutil.ReadAll(st)
+		if !st.Partial || st.Offset != 100 || st.Size != 1000 || !bytes.Equal(b, blob[100:200]) {
+			t.Errorf("Resource.Stream() unexpected range %+v, %d bytes", st, len(b))
+		}
+	})
+
+	t.Run("success if-range mismatch", func(t *testing.T) {
+		r, _ := g.Go("/blob")
+		st, err := r.Stream(ctx, resource.Range(100, -1), resource.IfRange(`"v0"`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer st.Close()
+
+		b, _ := ioutil.ReadAll(st)
+		if st.Partial || len(b) != len(blob) {
+			t.Errorf("Resource.Stream() should send the whole body when If-Range fails, got %+v", st)
+		}
+	})
+
+	t.Run("success resume", func(t *testing.T) {
+		ranges = []string{}
+		times = []time.Time{}
+		r, _ := g.Go("/broken")
+		w := make(memoryWriterAt, len(blob))
+
+		n, err := r.Download(ctx, w, resource.DownloadBackoff(50*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != int64(len(blob)) || !bytes.Equal(w, blob) {
+			t.Errorf("Resource.Download() expected the whole blob, got %d bytes", n)
+		}
+
+		if len(ranges) != 2 || ranges[1] != `bytes=300- "v1"` {
+			t.Errorf("Resource.Download() should resume at 300 with If-Range, got %q", ranges)
+		}
+
+		if len(times) == 2 && times[1].Sub(times[0]) < 50*time.Millisecond {
+			t.Errorf("Resource.Download() should wait before resuming, got %v", times[1].Sub(times[0]))
+		}
+	})
+
+	t.Run("success resume complete", func(t *testing.T) {
+		ranges = []string{}
+		r, _ := g.Go("/blob")
+		w := make(memoryWriterAt, len(blob))
+
+		n, err := r.Download(ctx, w, resource.ResumeAt(int64(len(blob))), resource.DownloadIfRange(`"v1"`))
+		if err != nil {
+			t.Fatalf("Resource.Download() should not error on a complete transfer, got %v", err)
+		}
+
+		if n != int64(len(blob)) || len(ranges) != 1 {
+			t.Errorf("Resource.Download() expected %d bytes in 1 request, got %d in %q", len(blob), n, ranges)
+		}
+	})
+
+	t.Run("error resume beyond", func(t *testing.T) {
+		r, _ := g.Go("/blob")
+		w := make(memoryWriterAt, len(blob)+10)
+
+		_, err := r.Download(ctx, w, resource.ResumeAt(int64(len(blob)+10)), resource.DownloadIfRange(`"v1"`))
+
+		var re *resource.ResponseError
+		if !errors.As(err, &re) || re.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+			t.Errorf("Resource.Download() expected a 416 error, got %v", err)
+		}
+	})
+}
+
+func Test_Getting_Subscribe(t *testing.T) {
+	gets := 0
+	lastIDs := []string{}
+	next := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/articles/1":
+			gets++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(fmt.Sprintf(`{"version": %d, "_links": {"self": {"href": "/articles/1"}, "monitor": {"href": "/events/1"}}}`, gets)))
+		case "/events/1":
+			lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+			w.Header().Set("Content-Type", "text/event-stream")
+
+			if len(lastIDs) == 1 {
+				// Ask for a quick reconnection, then drop the stream.
+				w.Write([]byte("retry: 10\nid: 1\nevent: changed\ndata: /articles/1\n\n"))
+				return
+			}
+
+			// The resource changes while updates are applied, wait until
+			// the test looked at the first one.
+			<-next
+			w.Write([]byte("id: 2\ndata: {\"version\": 99, \"_links\": {\"self\": {\"href\": \"/articles/1\"}}}\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	r, _ := g.Go("/articles/1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := r.Subscribe(ctx, resource.ApplyPushed("application/hal+json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := <-c
+	if u.Err != nil || u.Pushed || u.Event.ID != "1" || u.Event.Type != "changed" {
+		t.Errorf("Resource.Subscribe() unexpected first update %+v", u)
+	}
+
+	if b, _ := r.Get(); b.(representor.HALBody).Properties["version"] != float64(2) {
+		t.Errorf("Resource.Subscribe() should fetch the resource again, got %v", b)
+	}
+	close(next)
+
+	u = <-c
+	if u.Err != nil || !u.Pushed || u.Event.ID != "2" {
+		t.Errorf("Resource.Subscribe() unexpected second update %+v", u)
+	}
+
+	if b, _ := r.Get(); b.(representor.HALBody).Properties["version"] != float64(99) {
+		t.Errorf("Resource.Subscribe() should apply the pushed representation, got %v", b)
+	}
+
+	if len(lastIDs) != 2 || lastIDs[1] != "1" {
+		t.Errorf("Resource.Subscribe() should resume with Last-Event-ID 1, got %q", lastIDs)
+	}
+
+	cancel()
+	for range c {
+	}
+
+	if gets != 2 {
+		t.Errorf("Resource.Subscribe() expected 2 fetches, got %d", gets)
+	}
+}
+
+func Test_Getting_SubscribePushedConcurrent(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/articles/1":
+			http.Redirect(w, r, "/v2/articles/1", http.StatusFound)
+		case "/v2/articles/1":
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_links": {"self": {"href": "1"}, "monitor": {"href": "/events"}, "item": {"href": "items/1"}}}`))
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := 0; i < 20; i++ {
+				fmt.Fprintf(w, "id: %d\ndata: {\"version\": %d, \"_links\": {\"item\": {\"href\": \"items/1\"}}}\n\n", i, i)
+				w.(http.Flusher).Flush()
+			}
+			<-r.Context().Done()
+		}
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	r, _ := g.Go("/articles/1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := r.Subscribe(ctx, resource.ApplyPushed("application/hal+json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The resource is used while the updates are applied.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for ctx.Err() == nil {
+			r.Get()
+			r.Link("item")
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		if u := <-c; u.Err != nil || !u.Pushed {
+			t.Errorf("Resource.Subscribe() unexpected update %+v", u)
+		}
+	}
+
+	cancel()
+	<-done
+	for range c {
+	}
+
+	l, err := r.Link("item")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h, _ := l.Resolve(); h != s.URL+"/v2/articles/items/1" {
+		t.Errorf("Resource.Subscribe() should resolve pushed links against the final uri, got %s", h)
+	}
+}
+
+func Test_Getting_SubscribePushedWatch(t *testing.T) {
+	gets := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/articles/1":
+			gets++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"version": 1, "_links": {"self": {"href": "/articles/1"}, "monitor": {"href": "/events"}}}`))
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("data: {\"version\": 2, \"_links\": {\"self\": {\"href\": \"/articles/1\"}}}\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	r, _ := g.Go("/articles/1")
+
+	seen := []interface{}{}
+	r.Watch(func(x *resource.Resource) {
+		b, _ := x.Get()
+		seen = append(seen, b.(representor.HALBody).Properties["version"])
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := r.Subscribe(ctx, resource.ApplyPushed("application/hal+json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u := <-c; u.Err != nil || !u.Pushed {
+		t.Errorf("Resource.Subscribe() unexpected update %+v", u)
+	}
+
+	cancel()
+	for range c {
+	}
+
+	if len(seen) != 1 || seen[0] != float64(2) {
+		t.Errorf("Resource.Watch() should be called once with the pushed representation, got %v", seen)
+	}
+
+	if gets != 1 {
+		t.Errorf("Resource.Subscribe() should not fetch a pushed representation, got %d fetches", gets)
+	}
+}
+
+func Test_Getting_SubscribeNoMonitor(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(`{"_links": {"self": {"href": "/"}}}`))
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+	r, _ := g.Go("")
+
+	if _, err := r.Subscribe(context.Background()); err != resource.ErrNoMonitor {
+		t.Errorf("Resource.Subscribe() expected ErrNoMonitor, got %v", err)
+	}
+}
+
+func Test_Getting_SubscribeInvalidations(t *testing.T) {
+	var s *httptest.Server
+	conns := 0
+	messages := []string{`{"uris": ["/articles/1"]}`, "/articles/2\n"}
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ws":
+			c, err := websocket.Accept(w, r)
+			if err != nil {
+				return
+			}
+
+			// Send one message per connection and drop it, the client has
+			// to reconnect for the next one.
+			if conns < len(messages) {
+				c.WriteMessage(websocket.TextMessage, []byte(messages[conns]))
+			}
+			conns++
+			c.Close()
+		default:
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `"}, "monitor": [{"href": "/events"}, {"href": "ws` + strings.TrimPrefix(s.URL, "http") + `/ws"}]}}`))
+		}
+	}))
+	defer s.Close()
+
+	reconnects := 0
+	g, _ := New(s.URL, WithEventHook(func(e Event) {
+		if e.Type == EventReconnecting {
+			reconnects++
+		}
+	}))
+
+	changed := make(chan string, 2)
+	for _, p := range []string{"/articles/1", "/articles/2"} {
+		r, _ := g.Go(p)
+		if _, err := r.Get(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Reconnecting invalidates the articles again, those are not
+		// waited for.
+		r.Watch(func(r *resource.Resource) {
+			select {
+			case changed <- r.URI.Path:
+			default:
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done, err := g.SubscribeInvalidations(ctx, ReconnectBackoff(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]bool{}
+	for len(got) < 2 {
+		select {
+		case p := <-changed:
+			got[p] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("Getting.SubscribeInvalidations() timed out waiting for invalidations")
+		}
+	}
+
+	if !got["/articles/1"] || !got["/articles/2"] {
+		t.Errorf("Getting.SubscribeInvalidations() expected both articles invalidated, got %v", got)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Getting.SubscribeInvalidations() expected to end with the context, got %v", err)
+	}
+
+	for _, p := range []string{"/articles/1", "/articles/2"} {
+		if r, _ := g.Go(p); r.Representor != nil {
+			t.Errorf("Getting.SubscribeInvalidations() should invalidate %s", p)
+		}
+	}
+
+	if reconnects < 1 {
+		t.Errorf("Getting.SubscribeInvalidations() expected to reconnect, got %d", reconnects)
+	}
+}
+
+func Test_Getting_SubscribeInvalidationsReconnect(t *testing.T) {
+	var s *httptest.Server
+	conns := make(chan struct{}, 10)
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ws":
+			c, err := websocket.Accept(w, r)
+			if err != nil {
+				return
+			}
+
+			// Drop the first connection right away, keep the next one
+			// open.
+			conns <- struct{}{}
+			if len(conns) > 1 {
+				<-r.Context().Done()
+			}
+			c.Close()
+		default:
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `"}, "monitor": {"href": "ws` + strings.TrimPrefix(s.URL, "http") + `/ws"}}}`))
+		}
+	}))
+	defer s.Close()
+
+	g, _ := New(s.URL)
+
+	t.Run("error zero delay", func(t *testing.T) {
+		if _, err := g.SubscribeInvalidations(context.Background(), ReconnectBackoff(RetryPolicy{})); err == nil {
+			t.Error("Getting.SubscribeInvalidations() should error without a reconnect delay")
+		}
+	})
+
+	t.Run("success invalidate", func(t *testing.T) {
+		r, _ := g.Go("/articles/1")
+		if _, err := r.Get(); err != nil {
+			t.Fatal(err)
+		}
+
+		changed := make(chan struct{}, 1)
+		stop := r.Watch(func(r *resource.Resource) {
+			changed <- struct{}{}
+		})
+		defer stop()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done, err := g.SubscribeInvalidations(ctx, ReconnectBackoff(RetryPolicy{BaseDelay: time.Millisecond}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// No message names the article, the notifications that may have
+		// been missed while reconnecting do.
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Getting.SubscribeInvalidations() should invalidate the resources after reconnecting")
+		}
+
+		cancel()
+		<-done
+	})
+}
+
+func Test_Getting_Reconnect(t *testing.T) {
+	attempts := []int{}
+	g, _ := New("http://localhost/", WithEventHook(func(e Event) {
+		attempts = append(attempts, e.Attempt)
+	}))
+
+	p := RetryPolicy{BaseDelay: time.Millisecond, MaxAttempts: 3}
+	_, n, err := g.reconnect(context.Background(), "ws://127.0.0.1:1/", p, 2, errors.New("broken"))
+	if err == nil {
+		t.Error("Getting.reconnect() should error once the attempts are used up")
+	}
+
+	if n != 4 || fmt.Sprint(attempts) != "[3]" {
+		t.Errorf("Getting.reconnect() should carry on from earlier failures, got %d after %v", n, attempts)
+	}
+}
+
+func Test_Getting_SubscribeInvalidationsConcurrent(t *testing.T) {
+	var s *httptest.Server
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ws":
+			c, err := websocket.Accept(w, r)
+			if err != nil {
+				return
+			}
+
+			for i := 0; i < 20; i++ {
+				c.WriteMessage(websocket.TextMessage, []byte("/articles/1"))
+			}
+			<-r.Context().Done()
+			c.Close()
+		default:
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `"}, "monitor": {"href": "ws` + strings.TrimPrefix(s.URL, "http") + `/ws"}}}`))
+		}
+	}))
+	defer s.Close()
+
+	invalidated := make(chan struct{}, 20)
+	g, _ := New(s.URL, WithEventHook(func(e Event) {
+		if e.Type == EventInvalidated {
+			invalidated <- struct{}{}
+		}
+	}))
+
+	r, _ := g.Go("/articles/1")
+	if _, err := r.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The resource is used while the notifications invalidate it.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for ctx.Err() == nil {
+			r.Get()
+			r.Link("self")
+		}
+	}()
+
+	errs, err := g.SubscribeInvalidations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		select {
+		case <-invalidated:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Getting.SubscribeInvalidations() timed out waiting for invalidations")
+		}
+	}
+
+	cancel()
+	<-done
+
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Getting.SubscribeInvalidations() expected context.Canceled, got %v", err)
+	}
+}
+
+func Test_ParseInvalidations(t *testing.T) {
+	tests := []struct {
+		name string
+		b    string
+		want []string
+		err  bool
+	}{
+		{"success string", `"/a"`, []string{"/a"}, false},
+		{"success array", `["/a", "/b"]`, []string{"/a", "/b"}, false},
+		{"success object", `{"uri": "/a", "uris": ["/b"]}`, []string{"/a", "/b"}, false},
+		{"success lines", "/a\r\n\n/b\n", []string{"/a", "/b"}, false},
+		{"error json", `{"uri": `, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInvalidations([]byte(tt.b))
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseInvalidations() error = %v", err)
+			}
+
+			if !tt.err && fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ParseInvalidations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package getting
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/identbase/getting/pkg/link"
+)
+
+/*
+Invalidate discards everything the client knows about the resource at uri u.
+The cached response is deleted, and the resource will be fetched again the
+next time its representation is needed. */
+func (g *Getting) Invalidate(u string) {
+	g.Evict(u)
+
+	g.mu.Lock()
+	r, ok := g.resources.get(u)
+	g.mu.Unlock()
+
+	if ok {
+		r.Invalidate()
+	}
+
+	g.emit(Event{
+		Type: EventInvalidated,
+		URI:  u,
+	})
+}
+
+/*
+Evict deletes the cached response for the resource at uri u. The resource
+keeps its representation. */
+func (g *Getting) Evict(u string) {
+	if g.cache != nil {
+		g.cache.Delete(u)
+	}
+}
+
+/*
+invalidateFor invalidates the resources a response to an unsafe method
+changed: the request uri, the uris in the Location and Content-Location
+headers, and every link with rel="invalidates". */
+func (g *Getting) invalidateFor(req *http.Request, resp *http.Response) {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return
+	}
+
+	if resp.StatusCode >= 400 {
+		return
+	}
+
+	u := map[string]bool{
+		req.URL.String(): true,
+	}
+
+	for _, h := range []string{"Location", "Content-Location"} {
+		if v := resp.Header.Get(h); v != "" {
+			if l, err := req.URL.Parse(v); err == nil {
+				u[l.String()] = true
+			}
+		}
+	}
+
+	for _, h := range resp.Header["Link"] {
+		ls, err := link.ParseHeader(req.URL.String(), h)
+		if err != nil {
+			continue
+		}
+
+		for _, l := range ls {
+			if !strings.EqualFold(l.Rel, "invalidates") {
+				continue
+			}
+
+			if v, err := l.Resolve(); err == nil {
+				u[v] = true
+			}
+		}
+	}
+
+	for v := range u {
+		g.Invalidate(v)
+	}
+}
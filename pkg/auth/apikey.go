@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+/*
+Location describes where an API key is sent. */
+type Location int
+
+const (
+	// InHeader sends the API key as a request header.
+	InHeader Location = iota
+	// InQuery sends the API key as a query string parameter.
+	InQuery
+)
+
+/*
+APIKey authenticates requests with a static key, sent either as a header or
+as a query string parameter. A Getting client removes the header when a
+redirect leads to another origin. */
+type APIKey struct {
+	Name  string
+	Value string
+	In    Location
+}
+
+/*
+NewAPIKeyHeader creates a new APIKey provider sending the key as header n. */
+func NewAPIKeyHeader(n string, v string) *APIKey {
+	k := APIKey{
+		Name:  n,
+		Value: v,
+		In:    InHeader,
+	}
+
+	return &k
+}
+
+/*
+NewAPIKeyQuery creates a new APIKey provider sending the key as query
+parameter n. */
+func NewAPIKeyQuery(n string, v string) *APIKey {
+	k := APIKey{
+		Name:  n,
+		Value: v,
+		In:    InQuery,
+	}
+
+	return &k
+}
+
+/*
+Authenticate adds the key to the request. */
+func (k *APIKey) Authenticate(r *http.Request) error {
+	if k.Name == "" {
+		return errors.New("api key name unspecified")
+	}
+
+	switch k.In {
+	case InHeader:
+		r.Header.Set(k.Name, k.Value)
+	case InQuery:
+		// The existing query is kept as it is, the key is appended.
+		q := url.QueryEscape(k.Name) + "=" + url.QueryEscape(k.Value)
+		if r.URL.RawQuery != "" {
+			q = r.URL.RawQuery + "&" + q
+		}
+		r.URL.RawQuery = q
+	default:
+		return errors.New("unsupported api key location")
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"net/http"
+)
+
+/*
+Provider interface represents an authentication strategy. A Provider decorates
+an outgoing request with whatever credentials the server expects. */
+type Provider interface {
+	Authenticate(r *http.Request) error
+}
+
+/*
+Refresher interface is implemented by providers whose credentials can expire
+before they are told so. When the server responds with `401 Unauthorized` the
+client calls Refresh and retries the request once. */
+type Refresher interface {
+	Refresh() error
+}
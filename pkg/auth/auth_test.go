@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Providers_Authenticate(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      Provider
+		header string
+		want   string
+		query  string
+	}{
+		{
+			"basic",
+			NewBasic("user", "pass"),
+			"Authorization",
+			"Basic dXNlcjpwYXNz",
+			"",
+		},
+		{
+			"bearer",
+			NewBearer("token"),
+			"Authorization",
+			"Bearer token",
+			"",
+		},
+		{
+			"api key header",
+			NewAPIKeyHeader("X-API-Key", "secret"),
+			"X-API-Key",
+			"secret",
+			"",
+		},
+		{
+			"api key query",
+			NewAPIKeyQuery("api_key", "s&cret"),
+			"",
+			"",
+			"foo=b%20r&api_key=s%26cret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://localhost:8000/?foo=b%20r", nil)
+			if err := tt.p.Authenticate(req); err != nil {
+				t.Errorf("Provider.Authenticate() should not error, got %v", err)
+			}
+
+			if tt.header != "" && req.Header.Get(tt.header) != tt.want {
+				t.Errorf("Provider.Authenticate() %v expected '%v', got '%v'", tt.header, tt.want, req.Header.Get(tt.header))
+			}
+
+			if tt.query != "" && req.URL.RawQuery != tt.query {
+				t.Errorf("Provider.Authenticate() query expected '%v', got '%v'", tt.query, req.URL.RawQuery)
+			}
+		})
+	}
+}
+
+func Test_ClientCredentials_Authenticate(t *testing.T) {
+	issued := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "read write" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		issued++
+		tokens := []string{"first", "second"}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": tokens[issued-1],
+			"token_type":   "bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer s.Close()
+
+	c := NewClientCredentials(s.URL, "client", "secret", "read", "write")
+
+	for i, want := range []string{"Bearer first", "Bearer first"} {
+		req, _ := http.NewRequest("GET", "http://localhost:8000/", nil)
+		if err := c.Authenticate(req); err != nil {
+			t.Fatalf("ClientCredentials.Authenticate() should not error, got %v", err)
+		}
+
+		if req.Header.Get("Authorization") != want {
+			t.Errorf("ClientCredentials.Authenticate() #%d expected '%v', got '%v'", i, want, req.Header.Get("Authorization"))
+		}
+	}
+
+	if issued != 1 {
+		t.Errorf("ClientCredentials.Authenticate() should cache the token, requested %d", issued)
+	}
+
+	c.Refresh()
+
+	req, _ := http.NewRequest("GET", "http://localhost:8000/", nil)
+	if err := c.Authenticate(req); err != nil {
+		t.Fatalf("ClientCredentials.Authenticate() should not error, got %v", err)
+	}
+
+	if req.Header.Get("Authorization") != "Bearer second" {
+		t.Errorf("ClientCredentials.Refresh() expected 'Bearer second', got '%v'", req.Header.Get("Authorization"))
+	}
+}
+
+func Test_ClientCredentials_AuthenticateContext(t *testing.T) {
+	// A token endpoint that never answers.
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer s.Close()
+	defer close(release)
+
+	c := NewClientCredentials(s.URL, "client", "secret")
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			req, _ := http.NewRequest("GET", "http://localhost:8000/", nil)
+			errs <- c.Authenticate(req.WithContext(ctx))
+		}()
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("ClientCredentials.Authenticate() expected context.DeadlineExceeded, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("ClientCredentials.Authenticate() should stop when the context of the request is done")
+		}
+	}
+}
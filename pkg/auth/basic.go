@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"net/http"
+)
+
+/*
+Basic authenticates requests with HTTP Basic authentication (RFC 7617). */
+type Basic struct {
+	Username string
+	Password string
+}
+
+/*
+NewBasic creates a new Basic provider. */
+func NewBasic(u string, p string) *Basic {
+	b := Basic{
+		Username: u,
+		Password: p,
+	}
+
+	return &b
+}
+
+/*
+Authenticate sets the Authorization header on the request. */
+func (b *Basic) Authenticate(r *http.Request) error {
+	r.SetBasicAuth(b.Username, b.Password)
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+)
+
+/*
+Bearer authenticates requests with a static bearer token (RFC 6750). */
+type Bearer struct {
+	Token string
+}
+
+/*
+NewBearer creates a new Bearer provider. */
+func NewBearer(t string) *Bearer {
+	b := Bearer{
+		Token: t,
+	}
+
+	return &b
+}
+
+/*
+Authenticate sets the Authorization header on the request. */
+func (b *Bearer) Authenticate(r *http.Request) error {
+	if b.Token == "" {
+		return errors.New("bearer token unspecified")
+	}
+
+	r.Header.Set("Authorization", "Bearer "+b.Token)
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expirySkew is subtracted from the token lifetime so a token is never sent
+// right as it expires.
+const expirySkew = 10 * time.Second
+
+/*
+ClientCredentials authenticates requests with an OAuth2 access token obtained
+through the client credentials grant (RFC 6749 section 4.4). The token is
+cached until it expires, or until the server rejects it. */
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient is used to talk to the token endpoint, http.DefaultClient is
+	// used when it is nil.
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	// fetching holds a value while a token is requested.
+	fetching chan struct{}
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+/*
+NewClientCredentials creates a new ClientCredentials provider. */
+func NewClientCredentials(u string, id string, s string, sc ...string) *ClientCredentials {
+	c := ClientCredentials{
+		TokenURL:     u,
+		ClientID:     id,
+		ClientSecret: s,
+		Scopes:       sc,
+	}
+
+	return &c
+}
+
+/*
+Authenticate sets the Authorization header on the request, fetching a new
+access token first if there is no valid one cached. The token is requested
+with the context of the request, and one token request is made at a time: the
+other requests wait for it, or until their context is done. */
+func (c *ClientCredentials) Authenticate(r *http.Request) error {
+	t, ok := c.valid()
+	if !ok {
+		ctx := r.Context()
+
+		f := c.lock()
+		select {
+		case f <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() { <-f }()
+
+		// Another request may have fetched a token while this one waited.
+		if t, ok = c.valid(); !ok {
+			var err error
+			if t, err = c.fetch(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.Header.Set("Authorization", "Bearer "+t)
+
+	return nil
+}
+
+/*
+valid returns the cached access token, and false when there is no valid one. */
+func (c *ClientCredentials) valid() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == "" || (!c.expiry.IsZero() && time.Now().After(c.expiry)) {
+		return "", false
+	}
+
+	return c.token, true
+}
+
+/*
+lock returns the channel that is held while a token is requested. */
+func (c *ClientCredentials) lock() chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fetching == nil {
+		c.fetching = make(chan struct{}, 1)
+	}
+
+	return c.fetching
+}
+
+/*
+Refresh discards the cached access token, the next call to Authenticate will
+request a new one. */
+func (c *ClientCredentials) Refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = ""
+	c.expiry = time.Time{}
+
+	return nil
+}
+
+/*
+fetch requests a new access token from the token endpoint, and caches it. */
+func (c *ClientCredentials) fetch(ctx context.Context) (string, error) {
+	if c.TokenURL == "" {
+		return "", errors.New("token url unspecified")
+	}
+
+	v := url.Values{}
+	v.Set("grant_type", "client_credentials")
+	if len(c.Scopes) > 0 {
+		v.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	req, err := http.NewRequest("POST", c.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+
+	var t tokenResponse
+	if err := json.Unmarshal(body, &t); err != nil {
+		return "", err
+	}
+
+	if t.AccessToken == "" {
+		return "", errors.New("token response missing access_token")
+	}
+
+	if t.TokenType != "" && !strings.EqualFold(t.TokenType, "bearer") {
+		return "", fmt.Errorf("unsupported token type %q", t.TokenType)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = t.AccessToken
+	c.expiry = time.Time{}
+	if t.ExpiresIn > 0 {
+		c.expiry = time.Now().Add(time.Duration(t.ExpiresIn)*time.Second - expirySkew)
+	}
+
+	return c.token, nil
+}
@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Cache interface represents a store of HTTP responses. Entries are keyed by
+their uri, and a single uri can hold several entries when the response varies
+on request headers. */
+type Cache interface {
+	// Get returns the entry for uri u matching the request headers h.
+	Get(u string, h http.Header) (*Entry, bool)
+	// Set stores an entry, replacing the one with the same uri and vary key.
+	Set(e *Entry) error
+	// Delete removes every entry stored for uri u.
+	Delete(u string) error
+}
+
+/*
+Entry is a cached HTTP response. It holds everything that is needed to rebuild
+the response, and its representation, without talking to the server. */
+type Entry struct {
+	URI    string      `json:"uri"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	// Vary holds the values of the request headers named in the Vary
+	// response header, keyed by canonical header name.
+	Vary         map[string]string `json:"vary,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"lastModified,omitempty"`
+	Stored       time.Time         `json:"stored"`
+	// Location is the uri the response came from when the request to URI
+	// was permanently redirected, and Redirect the status of the redirect.
+	Location string `json:"location,omitempty"`
+	Redirect int    `json:"redirect,omitempty"`
+}
+
+/*
+NewEntry creates a new Entry from a response and its body. The request the
+response was sent for provides the uri and the Vary values. */
+func NewEntry(resp *http.Response, b []byte) *Entry {
+	e := Entry{
+		URI:          resp.Request.URL.String(),
+		Status:       resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         b,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Stored:       time.Now(),
+	}
+
+	for _, n := range varyNames(resp.Header) {
+		if e.Vary == nil {
+			e.Vary = map[string]string{}
+		}
+
+		e.Vary[n] = resp.Request.Header.Get(n)
+	}
+
+	return &e
+}
+
+/*
+Clone returns a copy of the entry that can be changed without changing the
+stored one. The body is shared, it is never changed. */
+func (e *Entry) Clone() *Entry {
+	c := *e
+	c.Header = e.Header.Clone()
+
+	if e.Vary != nil {
+		c.Vary = make(map[string]string, len(e.Vary))
+		for k, v := range e.Vary {
+			c.Vary[k] = v
+		}
+	}
+
+	return &c
+}
+
+/*
+Storable reports whether a response may be stored at all. */
+func Storable(resp *http.Response) bool {
+	if resp.Request == nil || resp.Request.Method != "GET" {
+		return false
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	cc := directives(resp.Header.Get("Cache-Control"))
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+
+	for _, n := range varyNames(resp.Header) {
+		if n == "*" {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+Key returns the vary key of the entry, entries for the same uri with the same
+key replace each other. */
+func (e *Entry) Key() string {
+	k := make([]string, 0, len(e.Vary))
+	for n, v := range e.Vary {
+		k = append(k, n+"="+v)
+	}
+
+	sort.Strings(k)
+
+	return strings.Join(k, "\n")
+}
+
+/*
+Matches reports whether the entry was stored for a request with headers
+matching h on every header named in Vary. */
+func (e *Entry) Matches(h http.Header) bool {
+	for n, v := range e.Vary {
+		if h.Get(n) != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+Fresh reports whether the entry may be used without asking the server,
+according to its Cache-Control max-age or Expires header. */
+func (e *Entry) Fresh(now time.Time) bool {
+	cc := directives(e.Header.Get("Cache-Control"))
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+
+	if v, ok := cc["max-age"]; ok {
+		s, err := strconv.Atoi(v)
+		if err != nil {
+			return false
+		}
+
+		return now.Before(e.Stored.Add(time.Duration(s) * time.Second))
+	}
+
+	if v := e.Header.Get("Expires"); v != "" {
+		t, err := http.ParseTime(v)
+		if err != nil {
+			return false
+		}
+
+		return now.Before(t)
+	}
+
+	return false
+}
+
+/*
+Response rebuilds the cached response for request req. When the entry was
+redirected, the request of the response is the redirected request, so that
+the representation resolves against the uri the response came from. */
+func (e *Entry) Response(req *http.Request) *http.Response {
+	if u, err := url.Parse(e.Location); err == nil && e.Location != "" {
+		r := req.Clone(req.Context())
+		r.URL = u
+		r.Host = u.Host
+		r.Response = &http.Response{
+			Status:     strconv.Itoa(e.Redirect) + " " + http.StatusText(e.Redirect),
+			StatusCode: e.Redirect,
+			Header:     http.Header{"Location": []string{e.Location}},
+			Request:    req,
+		}
+
+		req = r
+	}
+
+	resp := http.Response{
+		Status:        strconv.Itoa(e.Status) + " " + http.StatusText(e.Status),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+
+	return &resp
+}
+
+/*
+varyNames returns the canonical header names listed in the Vary header. */
+func varyNames(h http.Header) []string {
+	n := []string{}
+
+	for _, v := range h["Vary"] {
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			if p != "" {
+				n = append(n, http.CanonicalHeaderKey(p))
+			}
+		}
+	}
+
+	return n
+}
+
+/*
+NoStore returns true when the Cache-Control header in h has the no-store
+directive, which forbids storing the response. */
+func NoStore(h http.Header) bool {
+	_, ok := directives(h.Get("Cache-Control"))["no-store"]
+	return ok
+}
+
+/*
+directives parses a Cache-Control header. */
+func directives(v string) map[string]string {
+	d := map[string]string{}
+
+	for _, p := range strings.Split(v, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		kv := strings.SplitN(p, "=", 2)
+		k := strings.ToLower(kv[0])
+		if len(kv) == 2 {
+			d[k] = strings.Trim(kv[1], `"`)
+		} else {
+			d[k] = ""
+		}
+	}
+
+	return d
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func newEntry(u string, b string, vary map[string]string) *Entry {
+	e := Entry{
+		URI:    u,
+		Status: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": []string{"application/hal+json"},
+		},
+		Body:   []byte(b),
+		Vary:   vary,
+		Stored: time.Now(),
+	}
+
+	return &e
+}
+
+func Test_Cache_GetSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "getting-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	disk, err := NewDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caches := map[string]Cache{
+		"memory": NewMemory(10),
+		"disk":   disk,
+	}
+
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			c.Set(newEntry("http://localhost/a", "json", map[string]string{"Accept": "application/json"}))
+			c.Set(newEntry("http://localhost/a", "hal", map[string]string{"Accept": "application/hal+json"}))
+			c.Set(newEntry("http://localhost/b", "b", nil))
+
+			h := http.Header{}
+			h.Set("Accept", "application/hal+json")
+
+			e, ok := c.Get("http://localhost/a", h)
+			if !ok || string(e.Body) != "hal" {
+				t.Errorf("Cache.Get() expected the 'hal' variant, got %v", e)
+			}
+
+			h.Set("Accept", "text/html")
+			if _, ok := c.Get("http://localhost/a", h); ok {
+				t.Errorf("Cache.Get() should not match a different Vary value")
+			}
+
+			if e, ok := c.Get("http://localhost/b", h); !ok || string(e.Body) != "b" {
+				t.Errorf("Cache.Get() expected 'b', got %v", e)
+			}
+
+			c.Delete("http://localhost/a")
+
+			h.Set("Accept", "application/json")
+			if _, ok := c.Get("http://localhost/a", h); ok {
+				t.Errorf("Cache.Delete() should remove every variant")
+			}
+		})
+	}
+}
+
+func Test_Memory_Evict(t *testing.T) {
+	m := NewMemory(2)
+	m.Set(newEntry("http://localhost/a", "a", nil))
+	m.Set(newEntry("http://localhost/b", "b", nil))
+	m.Get("http://localhost/a", http.Header{})
+	m.Set(newEntry("http://localhost/c", "c", nil))
+
+	if _, ok := m.Get("http://localhost/b", http.Header{}); ok {
+		t.Errorf("Memory.Set() should evict the least recently used uri")
+	}
+
+	if _, ok := m.Get("http://localhost/a", http.Header{}); !ok {
+		t.Errorf("Memory.Set() should keep recently used uris")
+	}
+}
+
+func Test_Entry_Fresh(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   bool
+	}{
+		{"max-age", "Cache-Control", "max-age=60", true},
+		{"max-age expired", "Cache-Control", "max-age=0", false},
+		{"no-cache", "Cache-Control", "no-cache, max-age=60", false},
+		{"expires", "Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), true},
+		{"no headers", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEntry("http://localhost/", "", nil)
+			if tt.header != "" {
+				e.Header.Set(tt.header, tt.value)
+			}
+
+			if got := e.Fresh(time.Now()); got != tt.want {
+				t.Errorf("Entry.Fresh() expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Entry_Clone(t *testing.T) {
+	e := newEntry("http://localhost:8000/", "{}", map[string]string{"Accept": "application/hal+json"})
+	c := e.Clone()
+
+	c.Header.Set("Content-Type", "text/plain")
+	c.Vary["Accept"] = "text/plain"
+	c.Stored = time.Time{}
+
+	if e.Header.Get("Content-Type") != "application/hal+json" {
+		t.Errorf("Entry.Clone() should copy the header, got %v", e.Header)
+	}
+
+	if e.Vary["Accept"] != "application/hal+json" {
+		t.Errorf("Entry.Clone() should copy the vary values, got %v", e.Vary)
+	}
+
+	if e.Stored.IsZero() {
+		t.Errorf("Entry.Clone() should not share the entry")
+	}
+}
+
+func Test_Entry_Response(t *testing.T) {
+	e := newEntry("http://localhost:8000/old", "{}", nil)
+	e.Location = "http://localhost:8000/v2/home"
+	e.Redirect = http.StatusMovedPermanently
+
+	req, _ := http.NewRequest("GET", "http://localhost:8000/old", nil)
+	resp := e.Response(req)
+
+	if resp.Request.URL.String() != e.Location {
+		t.Errorf("Entry.Response() should have the redirected request, got %v", resp.Request.URL)
+	}
+
+	if resp.Request.Response == nil || resp.Request.Response.StatusCode != http.StatusMovedPermanently || resp.Request.Response.Request != req {
+		t.Errorf("Entry.Response() should keep the redirect, got %v", resp.Request.Response)
+	}
+}
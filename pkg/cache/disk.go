@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+/*
+Disk is a Cache that stores entries as JSON files in a directory, so they
+survive between runs. Every uri gets its own subdirectory holding one file per
+variant. */
+type Disk struct {
+	dir string
+}
+
+/*
+NewDisk creates a new Disk cache in directory d, creating it if needed. */
+func NewDisk(d string) (*Disk, error) {
+	if err := os.MkdirAll(d, 0755); err != nil {
+		return nil, err
+	}
+
+	c := Disk{
+		dir: d,
+	}
+
+	return &c, nil
+}
+
+/*
+Get returns the entry for uri u matching the request headers h. */
+func (d *Disk) Get(u string, h http.Header) (*Entry, bool) {
+	files, err := ioutil.ReadDir(d.path(u))
+	if err != nil {
+		return nil, false
+	}
+
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(d.path(u), f.Name()))
+		if err != nil {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(b, &e); err != nil {
+			continue
+		}
+
+		if e.URI == u && e.Matches(h) {
+			return &e, true
+		}
+	}
+
+	return nil, false
+}
+
+/*
+Set stores an entry, replacing the one with the same uri and vary key. */
+func (d *Disk) Set(e *Entry) error {
+	if err := os.MkdirAll(d.path(e.URI), 0755); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see half an entry.
+	f, err := ioutil.TempFile(d.path(e.URI), ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(d.path(e.URI), hash(e.Key())+".json"))
+}
+
+/*
+Delete removes every entry stored for uri u. */
+func (d *Disk) Delete(u string) error {
+	return os.RemoveAll(d.path(u))
+}
+
+/*
+path returns the directory holding the entries for uri u. */
+func (d *Disk) path(u string) string {
+	return filepath.Join(d.dir, hash(u))
+}
+
+/*
+hash returns the hex encoded SHA-256 of s. */
+func hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(h[:])
+}
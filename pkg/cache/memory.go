@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+/*
+Memory is an in-memory Cache that keeps the most recently used uris, up to a
+fixed size. */
+type Memory struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryItem struct {
+	uri      string
+	variants []*Entry
+}
+
+/*
+NewMemory creates a new Memory cache holding at most s uris. */
+func NewMemory(s int) *Memory {
+	m := Memory{
+		size:    s,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+
+	return &m
+}
+
+/*
+Get returns the entry for uri u matching the request headers h. */
+func (m *Memory) Get(u string, h http.Header) (*Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.entries[u]
+	if !ok {
+		return nil, false
+	}
+
+	m.order.MoveToFront(el)
+
+	for _, e := range el.Value.(*memoryItem).variants {
+		if e.Matches(h) {
+			return e, true
+		}
+	}
+
+	return nil, false
+}
+
+/*
+Set stores an entry, evicting the least recently used uri when the cache is
+full. */
+func (m *Memory) Set(e *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.entries[e.URI]; ok {
+		m.order.MoveToFront(el)
+
+		i := el.Value.(*memoryItem)
+		for n, v := range i.variants {
+			if v.Key() == e.Key() {
+				i.variants[n] = e
+				return nil
+			}
+		}
+
+		i.variants = append(i.variants, e)
+		return nil
+	}
+
+	m.entries[e.URI] = m.order.PushFront(&memoryItem{
+		uri:      e.URI,
+		variants: []*Entry{e},
+	})
+
+	for m.size > 0 && m.order.Len() > m.size {
+		el := m.order.Back()
+		m.order.Remove(el)
+		delete(m.entries, el.Value.(*memoryItem).uri)
+	}
+
+	return nil
+}
+
+/*
+Delete removes every entry stored for uri u. */
+func (m *Memory) Delete(u string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.entries[u]; ok {
+		m.order.Remove(el)
+		delete(m.entries, u)
+	}
+
+	return nil
+}
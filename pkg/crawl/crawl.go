@@ -0,0 +1,322 @@
+package crawl
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/identbase/getting/pkg/link"
+	"github.com/identbase/getting/pkg/resource"
+)
+
+/*
+Crawler walks the link graph of an API breadth-first, starting at the
+bookmark of a client. */
+type Crawler struct {
+	client      resource.Getting
+	maxDepth    int
+	hosts       map[string]bool
+	include     map[string]bool
+	exclude     map[string]bool
+	concurrency int
+}
+
+/*
+Option configures a Crawler object. */
+type Option func(*Crawler)
+
+/*
+MaxDepth only fetches resources at most n links away from the bookmark. Zero,
+the default, means no limit. */
+func MaxDepth(n int) Option {
+	return func(c *Crawler) {
+		c.maxDepth = n
+	}
+}
+
+/*
+AllowHosts sets the hosts whose resources are fetched. By default only the
+host of the bookmark is. Links to other hosts are recorded but not followed. */
+func AllowHosts(h ...string) Option {
+	return func(c *Crawler) {
+		for _, v := range h {
+			c.hosts[strings.ToLower(v)] = true
+		}
+	}
+}
+
+/*
+IncludeRels only follows links with one of the given rels. */
+func IncludeRels(rt ...string) Option {
+	return func(c *Crawler) {
+		for _, v := range rt {
+			c.include[link.NormalizeRel(v)] = true
+		}
+	}
+}
+
+/*
+ExcludeRels never follows links with one of the given rels. */
+func ExcludeRels(rt ...string) Option {
+	return func(c *Crawler) {
+		for _, v := range rt {
+			c.exclude[link.NormalizeRel(v)] = true
+		}
+	}
+}
+
+/*
+Concurrency sets how many resources are fetched at the same time, 4 by
+default. */
+func Concurrency(n int) Option {
+	return func(c *Crawler) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+/*
+New creates a new Crawler using client g. */
+func New(g resource.Getting, opts ...Option) *Crawler {
+	c := Crawler{
+		client:      g,
+		hosts:       map[string]bool{},
+		include:     map[string]bool{},
+		exclude:     map[string]bool{},
+		concurrency: 4,
+	}
+
+	for _, o := range opts {
+		o(&c)
+	}
+
+	return &c
+}
+
+/*
+Run crawls the API and returns the graph of the resources it found. Resources
+that fail to load are part of the graph with their error, Run only fails when
+the bookmark itself cannot be resolved or the context is done. */
+func (c *Crawler) Run(ctx context.Context) (*Graph, error) {
+	start, err := c.client.Go("")
+	if err != nil {
+		return nil, err
+	}
+
+	su, err := Normalize(start.Location().String())
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := c.hosts
+	if len(hosts) == 0 {
+		hosts = map[string]bool{strings.ToLower(start.Location().Host): true}
+	}
+
+	g := NewGraph(su)
+	g.Nodes[su] = &Node{URI: su, Depth: 0}
+
+	frontier := []target{{uri: su, r: start}}
+	for depth := 0; len(frontier) > 0; depth++ {
+		if err := ctx.Err(); err != nil {
+			return g, err
+		}
+
+		next := []target{}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, c.concurrency)
+
+		for _, t := range frontier {
+			wg.Add(1)
+			sem <- struct{}{}
+
+			go func(t target) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				found := c.visit(ctx, g, t, depth, hosts)
+
+				mu.Lock()
+				next = append(next, found...)
+				mu.Unlock()
+			}(t)
+		}
+
+		wg.Wait()
+
+		if c.maxDepth > 0 && depth >= c.maxDepth {
+			break
+		}
+
+		// Keep the traversal order stable between runs.
+		sort.Slice(next, func(i, j int) bool {
+			return next[i].uri < next[j].uri
+		})
+		frontier = next
+	}
+
+	return g, nil
+}
+
+/*
+target is a resource to visit, and the node it was found as. */
+type target struct {
+	uri string
+	r   *resource.Resource
+}
+
+/*
+visit fetches the resource of target t, records its links in the graph, and
+returns the resources that are discovered for the first time and should be
+fetched. */
+func (c *Crawler) visit(ctx context.Context, g *Graph, t target, depth int, hosts map[string]bool) []target {
+	// The uri of the resource may have changed since it was found, after a
+	// permanent redirect for example, the node keeps the uri it was found as.
+	u := t.uri
+
+	// Other workers may replace the representation, with one embedded in
+	// theirs, so it is only read once.
+	repr, err := t.r.Representation(ctx)
+
+	g.mu.Lock()
+	n, ok := g.Nodes[u]
+	if !ok {
+		n = &Node{URI: u, Depth: depth}
+		g.Nodes[u] = n
+	}
+	if err != nil {
+		n.Err = err
+	} else {
+		n.ContentType = repr.GetContentType()
+	}
+	n.Fetched = true
+	g.mu.Unlock()
+
+	if err != nil {
+		return nil
+	}
+
+	found := []target{}
+	for _, l := range repr.GetLinks("") {
+		rel := link.NormalizeRel(l.Rel)
+		if c.exclude[rel] || (len(c.include) > 0 && !c.include[rel]) {
+			continue
+		}
+
+		e := Edge{
+			From: u,
+			Rel:  l.Rel,
+			Link: l,
+		}
+
+		if l.Templated {
+			// A template is not a resource until it is expanded.
+			e.To = resolveTemplate(l)
+			g.addEdge(e)
+			continue
+		}
+
+		h, err := l.Resolve()
+		if err == nil {
+			h, err = Normalize(h)
+		}
+		if err != nil {
+			e.To = l.HRef
+			e.Err = err
+			g.addEdge(e)
+			continue
+		}
+
+		e.To = h
+		g.addEdge(e)
+
+		p, err := url.Parse(h)
+		if err != nil {
+			continue
+		}
+
+		g.mu.Lock()
+		_, seen := g.Nodes[h]
+		if !seen {
+			g.Nodes[h] = &Node{
+				URI:      h,
+				Depth:    depth + 1,
+				External: !hosts[strings.ToLower(p.Host)],
+			}
+		}
+		external := g.Nodes[h].External
+		g.mu.Unlock()
+
+		if seen || external {
+			continue
+		}
+
+		tr, err := c.client.Go(h)
+		if err != nil {
+			continue
+		}
+
+		found = append(found, target{uri: h, r: tr})
+	}
+
+	return found
+}
+
+/*
+resolveTemplate returns the href of templated link l resolved against its
+context, so that templates from different resources do not look the same. The
+literal part before the first expression is resolved, the expressions are
+kept. A template starting with an expression is returned as it is. */
+func resolveTemplate(l link.Link) string {
+	i := strings.IndexByte(l.HRef, '{')
+	if i == 0 {
+		return l.HRef
+	}
+
+	if i < 0 {
+		i = len(l.HRef)
+	}
+
+	p := l
+	p.HRef = l.HRef[:i]
+
+	h, err := p.Resolve()
+	if err != nil {
+		return l.HRef
+	}
+
+	return h + l.HRef[i:]
+}
+
+/*
+Normalize returns the form of uri u used to tell resources apart: the scheme
+and host are lower cased, default ports, fragments and empty paths are
+removed, and query parameters are sorted. */
+func Normalize(u string) (string, error) {
+	p, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+
+	p.Scheme = strings.ToLower(p.Scheme)
+	p.Host = strings.ToLower(p.Host)
+	p.Fragment = ""
+
+	if (p.Scheme == "http" && strings.HasSuffix(p.Host, ":80")) || (p.Scheme == "https" && strings.HasSuffix(p.Host, ":443")) {
+		p.Host = p.Host[:strings.LastIndex(p.Host, ":")]
+	}
+
+	if p.Path == "" && p.Opaque == "" {
+		p.Path = "/"
+	}
+
+	if p.RawQuery != "" {
+		p.RawQuery = p.Query().Encode()
+	}
+
+	return p.String(), nil
+}
@@ -0,0 +1,245 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/identbase/getting"
+	"github.com/identbase/getting/pkg/link"
+)
+
+func newServer() *httptest.Server {
+	pages := map[string]string{
+		"/": `{"_links": {
+			"self": {"href": "/", "title": "Home"},
+			"articles": {"href": "/articles", "title": "Articles"},
+			"search": {"href": "/search{?q}", "title": "Search", "templated": true},
+			"external": {"href": "http://example.invalid/", "title": "Elsewhere"},
+			"help": {"href": "/help#top", "title": "Help"}
+		}}`,
+		"/articles": `{"_links": {
+			"self": {"href": "/articles", "title": "Articles"},
+			"up": {"href": "/", "title": "Home"},
+			"item": [{"href": "/articles/1", "title": "One"}, {"href": "/articles/2", "title": "Two"}]
+		}}`,
+		"/articles/1": `{"_links": {"self": {"href": "/articles/1", "title": "One"}, "author": {"href": "/people/1", "title": "Author"}}}`,
+		"/people/1":   `{"_links": {"self": {"href": "/people/1", "title": "Author"}}}`,
+		"/help":       `{"_links": {"self": {"href": "/help", "title": "Help"}}}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(p))
+	}))
+}
+
+func Test_Crawler_Run(t *testing.T) {
+	s := newServer()
+	defer s.Close()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		fetched []string
+		broken  []string
+	}{
+		{
+			"success all",
+			nil,
+			[]string{"/", "/articles", "/help", "/articles/1", "/articles/2", "/people/1"},
+			[]string{"/articles/2"},
+		},
+		{
+			"success max depth",
+			[]Option{MaxDepth(1)},
+			[]string{"/", "/articles", "/help"},
+			[]string{},
+		},
+		{
+			"success exclude rels",
+			[]Option{ExcludeRels("item", "help"), Concurrency(1)},
+			[]string{"/", "/articles"},
+			[]string{},
+		},
+		{
+			"success include rels",
+			[]Option{IncludeRels("articles", "item")},
+			[]string{"/", "/articles", "/articles/1", "/articles/2"},
+			[]string{"/articles/2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := getting.New(s.URL)
+
+			graph, err := New(g, tt.opts...).Run(context.Background())
+			if err != nil {
+				t.Fatalf("Crawler.Run() errored with %v when it shouldnt have", err)
+			}
+
+			fetched := []string{}
+			for _, n := range graph.SortedNodes() {
+				if n.Fetched {
+					fetched = append(fetched, n.URI[len(s.URL):])
+				}
+			}
+
+			if len(fetched) != len(tt.fetched) {
+				t.Fatalf("Crawler.Run() expected to fetch %v, got %v", tt.fetched, fetched)
+			}
+
+			for _, want := range tt.fetched {
+				n, ok := graph.Nodes[s.URL+want]
+				if !ok || !n.Fetched {
+					t.Errorf("Crawler.Run() should have fetched %v", want)
+				}
+			}
+
+			broken := graph.Broken()
+			if len(broken) != len(tt.broken) {
+				t.Errorf("Graph.Broken() expected %v, got %v", tt.broken, broken)
+			}
+		})
+	}
+}
+
+func Test_Crawler_Graph(t *testing.T) {
+	s := newServer()
+	defer s.Close()
+
+	g, _ := getting.New(s.URL)
+	graph, err := New(g).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := graph.Nodes["http://example.invalid/"]; n == nil || !n.External || n.Fetched {
+		t.Errorf("Crawler.Run() should record external resources without fetching them, got %v", n)
+	}
+
+	templated := 0
+	for _, e := range graph.Edges {
+		if e.Link.Templated {
+			templated++
+			if e.To != s.URL+"/search{?q}" {
+				t.Errorf("Crawler.Run() should resolve the template of templated links, got %v", e.To)
+			}
+		}
+
+		if e.Rel == "help" && e.To != s.URL+"/help" {
+			t.Errorf("Crawler.Run() should normalize uris, got %v", e.To)
+		}
+	}
+
+	if templated != 1 {
+		t.Errorf("Crawler.Run() expected 1 templated edge, got %d", templated)
+	}
+
+	if o := graph.Orphans([]string{s.URL + "/help", s.URL + "/admin"}); len(o) != 1 || o[0] != s.URL+"/admin" {
+		t.Errorf("Graph.Orphans() expected [%v/admin], got %v", s.URL, o)
+	}
+}
+
+func Test_Crawler_SharedResources(t *testing.T) {
+	// Every item embeds the others, so workers store representations on the
+	// resources other workers are visiting.
+	items := []string{}
+	for i := 0; i < 8; i++ {
+		items = append(items, fmt.Sprintf(`{"href": "/items/%d"}`, i))
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"_links": {"self": {"href": "/"}, "item": [` + strings.Join(items, ",") + `]}}`))
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/new":
+			w.Write([]byte(`{"_links": {"self": {"href": "/new"}}}`))
+		default:
+			embedded := []string{}
+			for i := 0; i < 8; i++ {
+				embedded = append(embedded, fmt.Sprintf(`{"_links": {"self": {"href": "/items/%d"}, "up": {"href": "/old"}}}`, i))
+			}
+
+			w.Write([]byte(`{"_links": {"self": {"href": "` + r.URL.Path + `"}, "up": {"href": "/old"}}, "_embedded": {"item": [` + strings.Join(embedded, ",") + `]}}`))
+		}
+	}))
+	defer s.Close()
+
+	g, _ := getting.New(s.URL, getting.WithPermanentRedirects(getting.UpdateURI))
+
+	// The resource at /old is at /new once it was fetched.
+	old, _ := g.Go("/old")
+	if _, err := old.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	graph, err := New(g, Concurrency(8)).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := graph.Nodes[s.URL+"/old"]; n == nil || !n.Fetched || n.Err != nil {
+		t.Errorf("Crawler.Run() should record moved resources under the uri they were found as, got %v", n)
+	}
+
+	if len(graph.Broken()) != 0 {
+		t.Errorf("Crawler.Run() should not break on shared resources, got %v", graph.Broken())
+	}
+}
+
+func Test_Normalize(t *testing.T) {
+	tests := []struct {
+		u    string
+		want string
+	}{
+		{"HTTP://Example.ORG:80", "http://example.org/"},
+		{"https://example.org:443/a#frag", "https://example.org/a"},
+		{"http://example.org/a?b=2&a=1", "http://example.org/a?a=1&b=2"},
+		{"http://example.org:8080/A", "http://example.org:8080/A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.u, func(t *testing.T) {
+			if n, _ := Normalize(tt.u); n != tt.want {
+				t.Errorf("Normalize() expected '%v', got '%v'", tt.want, n)
+			}
+		})
+	}
+}
+
+func Test_Crawler_ResolveTemplate(t *testing.T) {
+	tests := []struct {
+		c    string
+		href string
+		want string
+	}{
+		{"http://example.org/a/", "items{?q}", "http://example.org/a/items{?q}"},
+		{"http://example.org/b/", "items{?q}", "http://example.org/b/items{?q}"},
+		{"http://example.org/a/", "/search{?q}", "http://example.org/search{?q}"},
+		{"http://example.org/a/", "{+base}/x", "{+base}/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.c+tt.href, func(t *testing.T) {
+			l := link.Link{Context: tt.c, HRef: tt.href, Templated: true}
+			if h := resolveTemplate(l); h != tt.want {
+				t.Errorf("resolveTemplate() expected '%v', got '%v'", tt.want, h)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package crawl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+nodeIDs assigns a stable identifier to every node, and to every edge target
+that is not a node, like link templates. */
+func (g *Graph) nodeIDs() ([]string, map[string]string) {
+	order := []string{}
+	ids := map[string]string{}
+
+	add := func(u string) {
+		if _, ok := ids[u]; !ok {
+			ids[u] = fmt.Sprintf("n%d", len(order))
+			order = append(order, u)
+		}
+	}
+
+	for _, n := range g.SortedNodes() {
+		add(n.URI)
+	}
+
+	for _, e := range g.SortedEdges() {
+		add(e.To)
+	}
+
+	return order, ids
+}
+
+/*
+label returns how the resource at uri u is shown in a diagram, its uri and
+media type. */
+func (g *Graph) label(u string) string {
+	n, ok := g.Nodes[u]
+	if !ok {
+		return u
+	}
+
+	switch {
+	case n.Err != nil:
+		return u + "\n(error)"
+	case n.ContentType != "":
+		return u + "\n" + n.ContentType
+	default:
+		return u
+	}
+}
+
+/*
+WriteDOT writes the graph in the GraphViz DOT language. Edges are labelled by
+rel, templated links are dashed and broken resources are red. */
+func (g *Graph) WriteDOT(w io.Writer) error {
+	order, ids := g.nodeIDs()
+
+	b := &strings.Builder{}
+	b.WriteString("digraph api {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, u := range order {
+		attrs := "label=" + dotQuote(g.label(u))
+
+		n, ok := g.Nodes[u]
+		switch {
+		case !ok:
+			attrs += ", style=dashed"
+		case n.Err != nil:
+			attrs += ", color=red"
+		case n.External:
+			attrs += ", style=dotted"
+		}
+
+		fmt.Fprintf(b, "  %s [%s];\n", ids[u], attrs)
+	}
+
+	for _, e := range g.SortedEdges() {
+		attrs := "label=" + dotQuote(e.Rel)
+		if e.Link.Templated {
+			attrs += ", style=dashed"
+		}
+
+		fmt.Fprintf(b, "  %s -> %s [%s];\n", ids[e.From], ids[e.To], attrs)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+/*
+WriteMermaid writes the graph as a Mermaid flowchart. Edges are labelled by
+rel, templated links are dotted. */
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	order, ids := g.nodeIDs()
+
+	b := &strings.Builder{}
+	b.WriteString("flowchart LR\n")
+
+	for _, u := range order {
+		fmt.Fprintf(b, "  %s[\"%s\"]\n", ids[u], mermaidEscape(strings.Replace(g.label(u), "\n", "<br/>", -1)))
+	}
+
+	for _, e := range g.SortedEdges() {
+		arrow := "-->"
+		if e.Link.Templated {
+			arrow = "-.->"
+		}
+
+		fmt.Fprintf(b, "  %s %s|\"%s\"| %s\n", ids[e.From], arrow, mermaidEscape(e.Rel), ids[e.To])
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+/*
+jsonNode is a node of the JSON adjacency list. */
+type jsonNode struct {
+	URI         string     `json:"uri"`
+	ContentType string     `json:"contentType,omitempty"`
+	Depth       int        `json:"depth"`
+	Fetched     bool       `json:"fetched"`
+	External    bool       `json:"external,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Links       []jsonEdge `json:"links"`
+}
+
+/*
+jsonEdge is an edge of the JSON adjacency list. */
+type jsonEdge struct {
+	Rel       string `json:"rel"`
+	To        string `json:"to"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+/*
+WriteJSON writes the graph as a JSON adjacency list: every node with the links
+going out of it. */
+func (g *Graph) WriteJSON(w io.Writer) error {
+	nodes := []*jsonNode{}
+	byURI := map[string]*jsonNode{}
+
+	for _, n := range g.SortedNodes() {
+		jn := jsonNode{
+			URI:         n.URI,
+			ContentType: n.ContentType,
+			Depth:       n.Depth,
+			Fetched:     n.Fetched,
+			External:    n.External,
+			Links:       []jsonEdge{},
+		}
+		if n.Err != nil {
+			jn.Error = n.Err.Error()
+		}
+
+		nodes = append(nodes, &jn)
+		byURI[n.URI] = &jn
+	}
+
+	for _, e := range g.SortedEdges() {
+		jn, ok := byURI[e.From]
+		if !ok {
+			continue
+		}
+
+		je := jsonEdge{
+			Rel:       e.Rel,
+			To:        e.To,
+			Title:     e.Link.Title,
+			Templated: e.Link.Templated,
+		}
+		if e.Err != nil {
+			je.Error = e.Err.Error()
+		}
+
+		jn.Links = append(jn.Links, je)
+	}
+
+	b, err := json.MarshalIndent(map[string]interface{}{
+		"root":  g.Root,
+		"nodes": nodes,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+/*
+dotQuote returns s as a DOT quoted string. */
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	return `"` + r.Replace(s) + `"`
+}
+
+/*
+mermaidEscape escapes the characters that end a Mermaid label. */
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "|", "#124;").Replace(s)
+}
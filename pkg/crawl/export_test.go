@@ -0,0 +1,91 @@
+package crawl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/identbase/getting/pkg/link"
+)
+
+func newGraph() *Graph {
+	g := NewGraph("http://localhost/")
+	g.Nodes["http://localhost/"] = &Node{URI: "http://localhost/", ContentType: "application/hal+json", Fetched: true}
+	g.Nodes["http://localhost/a"] = &Node{URI: "http://localhost/a", Depth: 1, Fetched: true, Err: errors.New("404")}
+	g.Edges = []Edge{
+		Edge{From: "http://localhost/", To: "http://localhost/a", Rel: "item", Link: link.Link{Rel: "item", Title: `Say "a"`}},
+		Edge{From: "http://localhost/", To: "/s{?q}", Rel: "search", Link: link.Link{Rel: "search", Templated: true}},
+	}
+
+	return g
+}
+
+func Test_Graph_Export(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(g *Graph, b *bytes.Buffer) error
+		want  string
+	}{
+		{
+			"dot",
+			func(g *Graph, b *bytes.Buffer) error { return g.WriteDOT(b) },
+			`digraph api {
+  node [shape=box];
+  n0 [label="http://localhost/\napplication/hal+json"];
+  n1 [label="http://localhost/a\n(error)", color=red];
+  n2 [label="/s{?q}", style=dashed];
+  n0 -> n1 [label="item"];
+  n0 -> n2 [label="search", style=dashed];
+}
+`,
+		},
+		{
+			"mermaid",
+			func(g *Graph, b *bytes.Buffer) error { return g.WriteMermaid(b) },
+			`flowchart LR
+  n0["http://localhost/<br/>application/hal+json"]
+  n1["http://localhost/a<br/>(error)"]
+  n2["/s{?q}"]
+  n0 -->|"item"| n1
+  n0 -.->|"search"| n2
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := tt.write(newGraph(), b); err != nil {
+				t.Fatalf("Graph export errored with %v when it shouldnt have", err)
+			}
+
+			if b.String() != tt.want {
+				t.Errorf("Graph export expected:\n%v\ngot:\n%v", tt.want, b.String())
+			}
+		})
+	}
+}
+
+func Test_Graph_WriteJSON(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := newGraph().WriteJSON(b); err != nil {
+		t.Fatalf("Graph.WriteJSON() errored with %v when it shouldnt have", err)
+	}
+
+	var o struct {
+		Root  string     `json:"root"`
+		Nodes []jsonNode `json:"nodes"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &o); err != nil {
+		t.Fatalf("Graph.WriteJSON() should write valid JSON, got %v", err)
+	}
+
+	if o.Root != "http://localhost/" || len(o.Nodes) != 2 {
+		t.Fatalf("Graph.WriteJSON() unexpected output %s", b.String())
+	}
+
+	if len(o.Nodes[0].Links) != 2 || o.Nodes[0].Links[1].Templated != true || o.Nodes[1].Error != "404" {
+		t.Errorf("Graph.WriteJSON() unexpected adjacency list %s", b.String())
+	}
+}
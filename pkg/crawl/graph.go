@@ -0,0 +1,141 @@
+package crawl
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/identbase/getting/pkg/link"
+)
+
+/*
+Graph is the result of a crawl: the resources found, keyed by normalized uri,
+and the links between them. */
+type Graph struct {
+	// Root is the normalized uri of the bookmark.
+	Root  string
+	Nodes map[string]*Node
+	Edges []Edge
+
+	mu sync.Mutex
+}
+
+/*
+Node is a resource found while crawling. */
+type Node struct {
+	URI string
+	// Depth is the number of links between the bookmark and the resource.
+	Depth       int
+	ContentType string
+	// Fetched is true when the crawler tried to fetch the resource.
+	Fetched bool
+	// External is true for resources on hosts that are not crawled.
+	External bool
+	// Err is why the resource could not be fetched.
+	Err error
+}
+
+/*
+Edge is a link from one resource to another. */
+type Edge struct {
+	From string
+	// To is the normalized uri of the target, the template resolved against
+	// the context when the link is templated, or the raw href when it cannot
+	// be resolved.
+	To   string
+	Rel  string
+	Link link.Link
+	// Err is why the href of the link could not be resolved.
+	Err error
+}
+
+/*
+NewGraph creates a new, empty, Graph rooted at uri r. */
+func NewGraph(r string) *Graph {
+	g := Graph{
+		Root:  r,
+		Nodes: map[string]*Node{},
+		Edges: []Edge{},
+	}
+
+	return &g
+}
+
+/*
+addEdge records an edge. */
+func (g *Graph) addEdge(e Edge) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Edges = append(g.Edges, e)
+}
+
+/*
+SortedNodes returns the nodes ordered by depth, then by uri. */
+func (g *Graph) SortedNodes() []*Node {
+	n := make([]*Node, 0, len(g.Nodes))
+	for _, v := range g.Nodes {
+		n = append(n, v)
+	}
+
+	sort.Slice(n, func(i, j int) bool {
+		if n[i].Depth != n[j].Depth {
+			return n[i].Depth < n[j].Depth
+		}
+
+		return n[i].URI < n[j].URI
+	})
+
+	return n
+}
+
+/*
+SortedEdges returns the edges ordered by source, rel and target. */
+func (g *Graph) SortedEdges() []Edge {
+	e := append([]Edge{}, g.Edges...)
+
+	sort.SliceStable(e, func(i, j int) bool {
+		if e[i].From != e[j].From {
+			return e[i].From < e[j].From
+		}
+
+		if e[i].Rel != e[j].Rel {
+			return e[i].Rel < e[j].Rel
+		}
+
+		return e[i].To < e[j].To
+	})
+
+	return e
+}
+
+/*
+Broken returns the nodes that could not be fetched. */
+func (g *Graph) Broken() []*Node {
+	b := []*Node{}
+	for _, n := range g.SortedNodes() {
+		if n.Err != nil {
+			b = append(b, n)
+		}
+	}
+
+	return b
+}
+
+/*
+Orphans returns the uris in known that the crawl did not reach, for example
+the routes of a server that no link points to. */
+func (g *Graph) Orphans(known []string) []string {
+	o := []string{}
+	for _, u := range known {
+		n, err := Normalize(u)
+		if err != nil {
+			n = u
+		}
+
+		if _, ok := g.Nodes[n]; !ok {
+			o = append(o, u)
+		}
+	}
+
+	return o
+}
@@ -0,0 +1,256 @@
+package gettingtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+)
+
+/*
+Resource is a resource of the fake API. It is served as application/hal+json
+and gets a self link unless one is defined. */
+type Resource struct {
+	server *Server
+	path   string
+
+	status     int
+	header     http.Header
+	properties map[string]interface{}
+	rels       []string
+	links      map[string][]map[string]interface{}
+	embedded   []embed
+	handlers   map[string]http.HandlerFunc
+}
+
+/*
+embed is a resource embedded under a rel. */
+type embed struct {
+	rel  string
+	path string
+}
+
+/*
+LinkOption sets an attribute of a link. */
+type LinkOption func(map[string]interface{})
+
+/*
+Title sets the title of a link. */
+func Title(t string) LinkOption {
+	return func(l map[string]interface{}) {
+		l["title"] = t
+	}
+}
+
+/*
+Name sets the name of a link. */
+func Name(n string) LinkOption {
+	return func(l map[string]interface{}) {
+		l["name"] = n
+	}
+}
+
+/*
+Type sets the media type of a link target. */
+func Type(t string) LinkOption {
+	return func(l map[string]interface{}) {
+		l["type"] = t
+	}
+}
+
+/*
+newResource creates a new Resource at path p of server s. */
+func newResource(s *Server, p string) *Resource {
+	r := Resource{
+		server:     s,
+		path:       p,
+		status:     http.StatusOK,
+		header:     http.Header{},
+		properties: map[string]interface{}{},
+		rels:       []string{},
+		links:      map[string][]map[string]interface{}{},
+		embedded:   []embed{},
+		handlers:   map[string]http.HandlerFunc{},
+	}
+
+	return &r
+}
+
+/*
+Property sets property k of the resource to v, any value encoding/json
+can marshal. */
+func (r *Resource) Property(k string, v interface{}) *Resource {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.properties[k] = v
+	return r
+}
+
+/*
+Link adds a link with relation rt to href h. Several links with the same rel
+are served as an array. */
+func (r *Resource) Link(rt string, h string, opts ...LinkOption) *Resource {
+	l := map[string]interface{}{"href": h}
+	for _, o := range opts {
+		o(l)
+	}
+
+	r.addLink(rt, l)
+	return r
+}
+
+/*
+Template adds a templated link with relation rt to the URI template t. */
+func (r *Resource) Template(rt string, t string, opts ...LinkOption) *Resource {
+	l := map[string]interface{}{"href": t, "templated": true}
+	for _, o := range opts {
+		o(l)
+	}
+
+	r.addLink(rt, l)
+	return r
+}
+
+/*
+addLink adds link l with relation rt. */
+func (r *Resource) addLink(rt string, l map[string]interface{}) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	if _, ok := r.links[rt]; !ok {
+		r.rels = append(r.rels, rt)
+	}
+
+	r.links[rt] = append(r.links[rt], l)
+}
+
+/*
+Embed embeds the resource at path p under relation rt, as it is defined when
+a request is served. A link to it is added as well. */
+func (r *Resource) Embed(rt string, p string) *Resource {
+	r.Link(rt, p)
+
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.embedded = append(r.embedded, embed{rel: rt, path: p})
+	return r
+}
+
+/*
+Status sets the status code of GET responses, 200 by default. */
+func (r *Resource) Status(c int) *Resource {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.status = c
+	return r
+}
+
+/*
+Header adds response header k with value v. */
+func (r *Resource) Header(k string, v string) *Resource {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.header.Add(k, v)
+	return r
+}
+
+/*
+On answers requests with method m with handler h, instead of the default
+response. Only GET and HEAD have a default response, other methods are not
+allowed unless a handler is set. */
+func (r *Resource) On(m string, h http.HandlerFunc) *Resource {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	r.handlers[m] = h
+	return r
+}
+
+/*
+serve answers request req, whose body b was already read. */
+func (r *Resource) serve(w http.ResponseWriter, req *http.Request, b []byte) {
+	r.server.mu.Lock()
+	h, ok := r.handlers[req.Method]
+	if ok {
+		r.server.mu.Unlock()
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		h(w, req)
+		return
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		r.server.mu.Unlock()
+
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	for k, v := range r.header {
+		w.Header()[k] = append([]string{}, v...)
+	}
+	status := r.status
+	body, err := json.Marshal(r.body(map[string]bool{}))
+	r.server.mu.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/hal+json")
+	w.WriteHeader(status)
+
+	if req.Method == http.MethodGet {
+		w.Write(body)
+	}
+}
+
+/*
+body returns the HAL document of the resource. Resources in seen are
+already being rendered and are not embedded again, which keeps cycles from
+recursing forever. The server lock must be held. */
+func (r *Resource) body(seen map[string]bool) map[string]interface{} {
+	seen[r.path] = true
+	defer delete(seen, r.path)
+
+	d := map[string]interface{}{}
+	for k, v := range r.properties {
+		d[k] = v
+	}
+
+	links := map[string]interface{}{}
+	if _, ok := r.links["self"]; !ok {
+		links["self"] = map[string]interface{}{"href": r.path}
+	}
+
+	for _, rt := range r.rels {
+		if l := r.links[rt]; len(l) == 1 {
+			links[rt] = l[0]
+		} else {
+			links[rt] = l
+		}
+	}
+
+	d["_links"] = links
+
+	embedded := map[string][]interface{}{}
+	for _, e := range r.embedded {
+		t, ok := r.server.resources[e.path]
+		if !ok || seen[e.path] {
+			continue
+		}
+
+		embedded[e.rel] = append(embedded[e.rel], t.body(seen))
+	}
+
+	if len(embedded) > 0 {
+		d["_embedded"] = embedded
+	}
+
+	return d
+}
@@ -0,0 +1,179 @@
+package gettingtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/identbase/getting"
+)
+
+/*
+Server is a fake hypermedia API for tests. Resources are defined with a
+fluent builder and served as HAL, every request is recorded so tests can
+assert on it. Resources can be added while the server is running. */
+type Server struct {
+	// URL is the base uri of the server, without a trailing slash.
+	URL string
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	resources map[string]*Resource
+	problems  map[string]problem
+	requests  []Request
+}
+
+/*
+Request is a request received by the Server. */
+type Request struct {
+	Method string
+	// Path is the path of the request uri and Query its raw query.
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+/*
+problem is an application/problem+json response (RFC 7807). */
+type problem struct {
+	Status int    `json:"status"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+/*
+NewServer creates and starts a new Server. It must be closed with Close. */
+func NewServer() *Server {
+	s := Server{
+		resources: map[string]*Resource{},
+		problems:  map[string]problem{},
+		requests:  []Request{},
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+
+	return &s
+}
+
+/*
+Close shuts the server down. */
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+/*
+Getting returns a Getting client whose bookmark is path p on the server. The
+test fails when the client cannot be created, with invalid options for
+example. */
+func (s *Server) Getting(t testing.TB, p string, opts ...getting.Option) *getting.Getting {
+	t.Helper()
+
+	g, err := getting.New(s.URL+p, opts...)
+	if err != nil {
+		t.Fatalf("getting.New() errored with %v", err)
+	}
+
+	return g
+}
+
+/*
+Resource returns the resource at path p, creating it when it does not exist
+yet. */
+func (s *Server) Resource(p string) *Resource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.resources[p]
+	if !ok {
+		r = newResource(s, p)
+		s.resources[p] = r
+	}
+
+	return r
+}
+
+/*
+Problem makes every request to path p fail with status code c and an
+application/problem+json body with title t and detail d. */
+func (s *Server) Problem(p string, c int, t string, d string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.problems[p] = problem{
+		Status: c,
+		Title:  t,
+		Detail: d,
+	}
+
+	return s
+}
+
+/*
+Requests returns every request received so far, oldest first. */
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request{}, s.requests...)
+}
+
+/*
+RequestsTo returns the requests received for path p with method m, any method
+when m is empty. */
+func (s *Server) RequestsTo(m string, p string) []Request {
+	r := []Request{}
+	for _, v := range s.Requests() {
+		if v.Path == p && (m == "" || v.Method == m) {
+			r = append(r, v)
+		}
+	}
+
+	return r
+}
+
+/*
+Reset forgets the requests received so far. */
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = []Request{}
+}
+
+/*
+serveHTTP records request req and answers it. */
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	b, _ := ioutil.ReadAll(req.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Header: req.Header.Clone(),
+		Body:   b,
+	})
+	pr, isProblem := s.problems[req.URL.Path]
+	r, ok := s.resources[req.URL.Path]
+	s.mu.Unlock()
+
+	if isProblem {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(pr.Status)
+		json.NewEncoder(w).Encode(pr)
+		return
+	}
+
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	r.serve(w, req, b)
+}
@@ -0,0 +1,132 @@
+package gettingtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/identbase/getting"
+	"github.com/identbase/getting/pkg/auth"
+	"github.com/identbase/getting/pkg/resource"
+)
+
+func Test_Server(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.Resource("/").
+		Link("articles", "/articles", Title("Articles")).
+		Template("search", "/search{?q}").
+		Link("broken", "/broken")
+	s.Resource("/articles").
+		Property("total", 1).
+		Embed("item", "/articles/1").
+		On(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Location", "/articles/2")
+			w.WriteHeader(http.StatusCreated)
+		})
+	s.Resource("/articles/1").
+		Property("title", "One").
+		Link("up", "/articles")
+	s.Resource("/search").Property("found", true)
+	s.Problem("/broken", http.StatusGone, "Gone", "it is not coming back")
+
+	g := s.Getting(t, "/")
+	ctx := context.Background()
+
+	t.Run("success follow embedded", func(t *testing.T) {
+		s.Reset()
+
+		r, err := g.Chain().Follow("articles").Follow("item").Resource(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.GetContext(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if r.URI.Path != "/articles/1" {
+			t.Errorf("expected /articles/1, got %s", r.URI.Path)
+		}
+
+		if n := len(s.RequestsTo("", "/articles/1")); n != 0 {
+			t.Errorf("expected the embedded item to be used, got %d requests", n)
+		}
+
+		if n := len(s.RequestsTo(http.MethodGet, "/articles")); n != 1 {
+			t.Errorf("expected 1 request to /articles, got %d", n)
+		}
+	})
+
+	t.Run("success template", func(t *testing.T) {
+		r, err := g.Follow("search", map[string]string{"q": "hal"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.GetContext(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		q := s.RequestsTo(http.MethodGet, "/search")
+		if len(q) != 1 || q[0].Query != "q=hal" {
+			t.Errorf("expected a search for q=hal, got %v", q)
+		}
+	})
+
+	t.Run("success handler", func(t *testing.T) {
+		r, err := g.Go("/articles")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.Post(ctx, "application/json", []byte(`{"title": "Two"}`)); err != nil {
+			t.Fatal(err)
+		}
+
+		p := s.RequestsTo(http.MethodPost, "/articles")
+		if len(p) != 1 || string(p[0].Body) != `{"title": "Two"}` {
+			t.Errorf("expected the post to be recorded, got %v", p)
+		}
+	})
+
+	t.Run("error problem", func(t *testing.T) {
+		r, err := g.Follow("broken", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = r.GetContext(ctx)
+
+		var re *resource.ResponseError
+		if !errors.As(err, &re) || re.StatusCode != http.StatusGone {
+			t.Errorf("expected a 410 response error, got %v", err)
+		}
+	})
+}
+
+/*
+recorder is a testing.TB that records whether the test failed instead of
+stopping it. */
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func Test_Server_Getting(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	r := recorder{TB: t}
+	if g := s.Getting(&r, "/", getting.WithAuth("api.example.org", auth.NewBearer("token"))); g != nil || !r.failed {
+		t.Errorf("Server.Getting() should fail the test on invalid options, got %v", g)
+	}
+}
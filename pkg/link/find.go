@@ -0,0 +1,94 @@
+package link
+
+import (
+	"mime"
+	"strings"
+)
+
+/*
+Matcher reports whether a link has some attribute, it is used to select links
+by more than their rel. */
+type Matcher func(l Link) bool
+
+/*
+WithType matches links whose media type hint is t. Parameters and case are
+ignored, so "text/csv" matches "text/CSV; charset=utf-8". */
+func WithType(t string) Matcher {
+	return func(l Link) bool {
+		return mediaType(l.Type) == mediaType(t)
+	}
+}
+
+/*
+WithName matches links named n. */
+func WithName(n string) Matcher {
+	return func(l Link) bool {
+		return l.Name == n
+	}
+}
+
+/*
+WithTitle matches links titled t. */
+func WithTitle(t string) Matcher {
+	return func(l Link) bool {
+		return l.Title == t
+	}
+}
+
+/*
+WithHreflang matches links whose target is in language h. A language range
+matches its subtags, "en" matches "en-US". */
+func WithHreflang(h string) Matcher {
+	return func(l Link) bool {
+		for _, v := range l.Hreflang {
+			if strings.EqualFold(v, h) || (len(v) > len(h) && strings.EqualFold(v[:len(h)+1], h+"-")) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+/*
+Filter returns the links matching every matcher. */
+func Filter(l []Link, m ...Matcher) []Link {
+	f := []Link{}
+
+	for _, v := range l {
+		if matches(v, m) {
+			f = append(f, v)
+		}
+	}
+
+	return f
+}
+
+/*
+Find returns the links with reltype rt that match every matcher. */
+func (s LinkSet) Find(rt string, m ...Matcher) []Link {
+	return Filter(s.Get(rt), m...)
+}
+
+/*
+matches reports whether l matches every matcher. */
+func matches(l Link, m []Matcher) bool {
+	for _, fn := range m {
+		if !fn(l) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+mediaType returns the lower cased media type without its parameters. */
+func mediaType(t string) string {
+	mt, _, err := mime.ParseMediaType(t)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(t))
+	}
+
+	return mt
+}
@@ -0,0 +1,395 @@
+package link
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+/*
+ParseHeader parses the value of an HTTP Link header (RFC 8288) into links. The
+context c is the uri of the response the header was received with. A link
+with several space separated relation types becomes one Link per rel. */
+func ParseHeader(c string, h string) ([]Link, error) {
+	l := []Link{}
+	p := headerParser{s: h}
+
+	for {
+		p.skip()
+		if p.done() {
+			return l, nil
+		}
+
+		if !p.consume('<') {
+			return nil, errors.New("link header: expected '<'")
+		}
+
+		i := strings.IndexByte(p.s[p.i:], '>')
+		if i < 0 {
+			return nil, errors.New("link header: unterminated uri reference")
+		}
+
+		href := p.s[p.i : p.i+i]
+		p.i += i + 1
+
+		params, err := p.params()
+		if err != nil {
+			return nil, err
+		}
+
+		ts := ""
+		if v := first(params["title*"]); v != "" {
+			ts, err = decodeExtValue(v)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var ext map[string][]string
+		for k, v := range params {
+			switch k {
+			case "rel", "anchor", "title", "title*", "type", "media", "hreflang", "profile", "deprecation":
+				continue
+			}
+
+			if ext == nil {
+				ext = map[string][]string{}
+			}
+			ext[k] = v
+		}
+
+		for _, rel := range strings.Fields(first(params["rel"])) {
+			l = append(l, Link{
+				Context:     c,
+				Anchor:      first(params["anchor"]),
+				HRef:        href,
+				Rel:         rel,
+				Title:       first(params["title"]),
+				TitleStar:   ts,
+				Type:        first(params["type"]),
+				Hreflang:    params["hreflang"],
+				Media:       first(params["media"]),
+				Profile:     first(params["profile"]),
+				Deprecation: first(params["deprecation"]),
+				Extensions:  ext,
+			})
+		}
+
+		p.skip()
+		if !p.done() && !p.consume(',') {
+			return nil, errors.New("link header: expected ','")
+		}
+	}
+}
+
+/*
+ParseHeaderSet parses the value of an HTTP Link header into a LinkSet. */
+func ParseHeaderSet(c string, h string) (LinkSet, error) {
+	l, err := ParseHeader(c, h)
+	if err != nil {
+		return nil, err
+	}
+
+	s := LinkSet{}
+	for _, v := range l {
+		s.Add(v.Rel, v)
+	}
+
+	return s, nil
+}
+
+/*
+Header serializes the link as the value of an HTTP Link header (RFC 8288).
+Only the target attributes the Link header knows about are written, HAL
+specific ones like Name and Templated are left out. A Title that is not plain
+ASCII is written as `title*`, and the rel is left out when it is empty. */
+func (l Link) Header() string {
+	var b strings.Builder
+
+	b.WriteString("<" + headerTarget.Replace(l.HRef) + ">")
+	if l.Rel != "" {
+		writeParam(&b, "rel", l.Rel)
+	}
+
+	if l.Anchor != "" {
+		writeParam(&b, "anchor", l.Anchor)
+	}
+
+	ts := l.TitleStar
+	if l.Title != "" {
+		if isASCII(l.Title) {
+			writeParam(&b, "title", l.Title)
+		} else if ts == "" {
+			ts = l.Title
+		}
+	}
+
+	if ts != "" {
+		b.WriteString("; title*=UTF-8''" + encodeExtValue(ts))
+	}
+
+	if l.Type != "" {
+		writeParam(&b, "type", l.Type)
+	}
+
+	for _, v := range l.Hreflang {
+		writeParam(&b, "hreflang", v)
+	}
+
+	if l.Media != "" {
+		writeParam(&b, "media", l.Media)
+	}
+
+	if l.Profile != "" {
+		writeParam(&b, "profile", l.Profile)
+	}
+
+	if l.Deprecation != "" {
+		writeParam(&b, "deprecation", l.Deprecation)
+	}
+
+	keys := make([]string, 0, len(l.Extensions))
+	for k := range l.Extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range l.Extensions[k] {
+			if strings.HasSuffix(k, "*") {
+				// Extended values are kept encoded.
+				b.WriteString("; " + k + "=" + v)
+			} else {
+				writeParam(&b, k, v)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+/*
+Header serializes every link of the set as the value of one HTTP Link header,
+ordered by rel. */
+func (s LinkSet) Header() string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := []string{}
+	for _, k := range keys {
+		for _, v := range s[k] {
+			if v.Rel == "" {
+				v.Rel = k
+			}
+
+			h = append(h, v.Header())
+		}
+	}
+
+	return strings.Join(h, ", ")
+}
+
+/*
+headerTarget percent-encodes the characters that would end the uri reference
+of a link early. */
+var headerTarget = strings.NewReplacer("<", "%3C", ">", "%3E")
+
+/*
+writeParam writes a `; name="value"` pair, quoting the value. */
+func writeParam(b *strings.Builder, n string, v string) {
+	b.WriteString("; " + n + "=\"")
+
+	for i := 0; i < len(v); i++ {
+		if v[i] == '"' || v[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(v[i])
+	}
+
+	b.WriteByte('"')
+}
+
+/*
+isASCII reports whether s only holds printable ASCII characters. */
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+encodeExtValue percent-encodes s for an RFC 8187 extended value, leaving only
+the attr-char characters as they are. */
+func encodeExtValue(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+		}
+	}
+
+	return b.String()
+}
+
+/*
+headerParser is a small cursor over a Link header value. */
+type headerParser struct {
+	s string
+	i int
+}
+
+/*
+done reports whether the whole value was read. */
+func (p *headerParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+/*
+skip moves past whitespace. */
+func (p *headerParser) skip() {
+	for !p.done() && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+/*
+consume moves past c if it is the next character. */
+func (p *headerParser) consume(c byte) bool {
+	if p.done() || p.s[p.i] != c {
+		return false
+	}
+
+	p.i++
+
+	return true
+}
+
+/*
+first returns the first value of a parameter. Only the first occurrence of a
+single valued parameter counts, as RFC 8288 requires for rel. */
+func first(v []string) string {
+	if len(v) == 0 {
+		return ""
+	}
+
+	return v[0]
+}
+
+/*
+params parses the `; name=value` pairs following a uri reference. */
+func (p *headerParser) params() (map[string][]string, error) {
+	params := map[string][]string{}
+
+	for {
+		p.skip()
+		if !p.consume(';') {
+			return params, nil
+		}
+
+		p.skip()
+		n := p.token()
+		if n == "" {
+			return nil, errors.New("link header: expected parameter name")
+		}
+		n = strings.ToLower(n)
+
+		v := ""
+		p.skip()
+		if p.consume('=') {
+			p.skip()
+			if !p.done() && p.s[p.i] == '"' {
+				q, err := p.quoted()
+				if err != nil {
+					return nil, err
+				}
+				v = q
+			} else {
+				v = p.token()
+			}
+		}
+
+		params[n] = append(params[n], v)
+	}
+}
+
+/*
+token reads a parameter name or unquoted value. */
+func (p *headerParser) token() string {
+	s := p.i
+	for !p.done() && !strings.ContainsRune(" \t;,=\"", rune(p.s[p.i])) {
+		p.i++
+	}
+
+	return p.s[s:p.i]
+}
+
+/*
+quoted reads a quoted string, removing the escapes. */
+func (p *headerParser) quoted() (string, error) {
+	p.i++
+
+	var b strings.Builder
+	for !p.done() {
+		c := p.s[p.i]
+		p.i++
+
+		switch c {
+		case '\\':
+			if p.done() {
+				return "", errors.New("link header: unterminated quoted string")
+			}
+			b.WriteByte(p.s[p.i])
+			p.i++
+		case '"':
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", errors.New("link header: unterminated quoted string")
+}
+
+/*
+decodeExtValue decodes an RFC 8187 extended value, `charset'lang'value`, as
+used by the `title*` attribute. */
+func decodeExtValue(v string) (string, error) {
+	p := strings.SplitN(v, "'", 3)
+	if len(p) != 3 {
+		return "", errors.New("link header: malformed extended value")
+	}
+
+	d, err := url.PathUnescape(p[2])
+	if err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(p[0]) {
+	case "utf-8":
+		return d, nil
+	case "iso-8859-1":
+		r := make([]rune, len(d))
+		for i := 0; i < len(d); i++ {
+			r[i] = rune(d[i])
+		}
+
+		return string(r), nil
+	default:
+		return "", errors.New("link header: unsupported charset " + p[0])
+	}
+}
@@ -6,14 +6,18 @@ import (
 	"github.com/yosida95/uritemplate"
 )
 
+/*
+LinkSet holds links keyed by rel. Registered relation types are matched
+case-insensitively, extension relation types exactly, see NormalizeRel. */
 type LinkSet map[string][]Link
 
 func (s LinkSet) Add(k string, v Link) {
+	k = NormalizeRel(k)
 	s[k] = append(s[k], v)
 }
 
 func (s LinkSet) Set(k string, v []Link) {
-	s[k] = v
+	s[NormalizeRel(k)] = v
 }
 
 func (s LinkSet) Get(k string) []Link {
@@ -21,7 +25,7 @@ func (s LinkSet) Get(k string) []Link {
 		return []Link{}
 	}
 
-	return s[k]
+	return s[NormalizeRel(k)]
 }
 
 func (s LinkSet) Values() []Link {
@@ -35,53 +39,103 @@ func (s LinkSet) Values() []Link {
 }
 
 func (s LinkSet) Has(k string) bool {
-	_, ok := s[k]
+	_, ok := s[NormalizeRel(k)]
 	return ok
 }
 
+/*
+Link is a typed connection between two resources (RFC 8288). Besides the
+context, target and relation type it holds every target attribute the link
+was found with, so nothing is lost whatever format it came from. */
 type Link struct {
 	Context   string
+	Anchor    string
 	HRef      string
 	Rel       string
 	Name      string
 	Templated bool
 	Title     string
-	Type      string
+	// TitleStar is the decoded `title*` attribute, a title that is not
+	// limited to ASCII.
+	TitleStar   string
+	Type        string
+	Hreflang    []string
+	Media       string
+	Profile     string
+	Deprecation string
+	// Extensions holds the target attributes this package does not know
+	// about, keyed by lower cased name.
+	Extensions map[string][]string
 }
 
+/*
+Expand expands a templated link with variables lv and resolves the result, a
+link that is not templated is only resolved. */
 func (l Link) Expand(lv map[string]string) (string, error) {
 	if !l.Templated {
 		return l.Resolve()
-	} else {
-		t, err := uritemplate.New(l.Context)
-		if err != nil {
-			return "", err
-		}
+	}
 
-		tv := uritemplate.Values{}
-		for k, v := range lv {
-			tv.Set(k, uritemplate.String(v))
-		}
+	t, err := uritemplate.New(l.HRef)
+	if err != nil {
+		return "", err
+	}
 
-		h, err := t.Expand(tv)
-		if err != nil {
-			return "", err
-		}
+	tv := uritemplate.Values{}
+	for k, v := range lv {
+		tv.Set(k, uritemplate.String(v))
+	}
+
+	h, err := t.Expand(tv)
+	if err != nil {
+		return "", err
+	}
+
+	return l.resolve(h)
+}
+
+/*
+Variables returns the names of the variables of a templated link, a link that
+is not templated has none. */
+func (l Link) Variables() ([]string, error) {
+	if !l.Templated {
+		return []string{}, nil
+	}
 
-		return h, nil
+	t, err := uritemplate.New(l.HRef)
+	if err != nil {
+		return nil, err
 	}
+
+	return t.Varnames(), nil
 }
 
+/*
+Resolve returns the absolute uri of the link target. The href is resolved
+against the anchor when one is set, itself resolved against the context. */
 func (l Link) Resolve() (string, error) {
+	return l.resolve(l.HRef)
+}
+
+/*
+resolve resolves the reference h against the base of the link. */
+func (l Link) resolve(h string) (string, error) {
 	u, err := url.Parse(l.Context)
 	if err != nil {
 		return "", err
 	}
 
-	h, err := u.Parse(l.HRef)
+	if l.Anchor != "" {
+		u, err = u.Parse(l.Anchor)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	t, err := u.Parse(h)
 	if err != nil {
 		return "", err
 	}
 
-	return h.String(), nil
+	return t.String(), nil
 }
@@ -1 +1,297 @@
 package link
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		h    string
+		want []Link
+		err  bool
+	}{
+		{
+			"success simple",
+			`</articles>; rel="invalidates"`,
+			[]Link{
+				Link{Context: "http://localhost/", HRef: "/articles", Rel: "invalidates"},
+			},
+			false,
+		},
+		{
+			"success many links and rels",
+			`</a>; rel="next prev"; title="A, \"quoted\"", </b>;rel=item;type=text/csv`,
+			[]Link{
+				Link{Context: "http://localhost/", HRef: "/a", Rel: "next", Title: `A, "quoted"`},
+				Link{Context: "http://localhost/", HRef: "/a", Rel: "prev", Title: `A, "quoted"`},
+				Link{Context: "http://localhost/", HRef: "/b", Rel: "item", Type: "text/csv"},
+			},
+			false,
+		},
+		{
+			"error missing uri reference",
+			`/a; rel="next"`,
+			nil,
+			true,
+		},
+		{
+			"error unterminated quote",
+			`</a>; rel="next`,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ParseHeader("http://localhost/", tt.h)
+			if tt.err && err == nil {
+				t.Errorf("ParseHeader() should error, got nil")
+			} else if !tt.err && err != nil {
+				t.Errorf("ParseHeader() errored with %v when it shouldnt have", err)
+			}
+
+			if len(l) != len(tt.want) {
+				t.Fatalf("ParseHeader() expected %d links, got %d", len(tt.want), len(l))
+			}
+
+			for i := range l {
+				if !reflect.DeepEqual(l[i], tt.want[i]) {
+					t.Errorf("ParseHeader() link %d expected %v, got %v", i, tt.want[i], l[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_LinkSet_Find(t *testing.T) {
+	s := LinkSet{}
+	s.Add("alternate", Link{HRef: "/export.json", Rel: "alternate", Type: "application/json"})
+	s.Add("alternate", Link{HRef: "/export.csv", Rel: "alternate", Type: "text/CSV; charset=utf-8"})
+	s.Add("alternate", Link{HRef: "/fr", Rel: "alternate", Name: "primary", Hreflang: []string{"fr-CA", "fr"}})
+	s.Add("alternate", Link{HRef: "/en", Rel: "alternate", Name: "primary", Hreflang: []string{"en-US"}})
+
+	tests := []struct {
+		name string
+		m    []Matcher
+		want []string
+	}{
+		{"type", []Matcher{WithType("text/csv")}, []string{"/export.csv"}},
+		{"name", []Matcher{WithName("primary")}, []string{"/fr", "/en"}},
+		{"hreflang range", []Matcher{WithHreflang("en")}, []string{"/en"}},
+		{"all matchers", []Matcher{WithName("primary"), WithHreflang("fr-ca")}, []string{"/fr"}},
+		{"no match", []Matcher{WithType("text/html")}, []string{}},
+		{"no matchers", nil, []string{"/export.json", "/export.csv", "/fr", "/en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := s.Find("alternate", tt.m...)
+
+			got := []string{}
+			for _, v := range l {
+				got = append(got, v.HRef)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LinkSet.Find() expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Link_Resolve(t *testing.T) {
+	tests := []struct {
+		name string
+		l    Link
+		v    map[string]string
+		want string
+	}{
+		{
+			"context",
+			Link{Context: "http://localhost/a/b", HRef: "c"},
+			nil,
+			"http://localhost/a/c",
+		},
+		{
+			"anchor",
+			Link{Context: "http://localhost/a/b", Anchor: "/x/y", HRef: "z"},
+			nil,
+			"http://localhost/x/z",
+		},
+		{
+			"templated",
+			Link{Context: "http://localhost/a/b", HRef: "/search{?q}", Templated: true},
+			map[string]string{"q": "hal links"},
+			"http://localhost/search?q=hal%20links",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := tt.l.Expand(tt.v)
+			if err != nil {
+				t.Errorf("Link.Expand() errored with %v when it shouldnt have", err)
+			}
+
+			if h != tt.want {
+				t.Errorf("Link.Expand() expected '%v', got '%v'", tt.want, h)
+			}
+		})
+	}
+}
+
+func Test_ParseHeader_TargetAttributes(t *testing.T) {
+	h := `</b>; rel=alternate; anchor="#a"; hreflang=en; hreflang=de; media=print; title*=UTF-8'de'n%c3%a4chstes; profile="http://localhost/p"; deprecation="http://localhost/d"; foo=bar`
+
+	l, err := ParseHeader("http://localhost/", h)
+	if err != nil {
+		t.Fatalf("ParseHeader() errored with %v when it shouldnt have", err)
+	}
+
+	want := Link{
+		Context:     "http://localhost/",
+		Anchor:      "#a",
+		HRef:        "/b",
+		Rel:         "alternate",
+		TitleStar:   "nächstes",
+		Hreflang:    []string{"en", "de"},
+		Media:       "print",
+		Profile:     "http://localhost/p",
+		Deprecation: "http://localhost/d",
+		Extensions:  map[string][]string{"foo": []string{"bar"}},
+	}
+
+	if len(l) != 1 || !reflect.DeepEqual(l[0], want) {
+		t.Errorf("ParseHeader() expected %v, got %v", want, l)
+	}
+}
+
+func Test_Link_Header(t *testing.T) {
+	tests := []struct {
+		name string
+		l    Link
+		want string
+	}{
+		{
+			"simple",
+			Link{HRef: "/a", Rel: "next"},
+			`</a>; rel="next"`,
+		},
+		{
+			"quoting",
+			Link{HRef: "/a", Rel: "next", Title: `Say "hi" \ bye`, Hreflang: []string{"en", "de"}},
+			`</a>; rel="next"; title="Say \"hi\" \\ bye"; hreflang="en"; hreflang="de"`,
+		},
+		{
+			"title star",
+			Link{HRef: "/a", Rel: "next", Title: "nächstes Kapitel"},
+			`</a>; rel="next"; title*=UTF-8''n%C3%A4chstes%20Kapitel`,
+		},
+		{
+			"empty rel",
+			Link{HRef: "/a", Type: "text/csv"},
+			`</a>; type="text/csv"`,
+		},
+		{
+			"angle brackets",
+			Link{HRef: "/search?q=<a>", Rel: "next"},
+			`</search?q=%3Ca%3E>; rel="next"`,
+		},
+		{
+			"extensions",
+			Link{HRef: "/a", Rel: "next", Extensions: map[string][]string{"z": []string{"1"}, "a": []string{"2"}}},
+			`</a>; rel="next"; a="2"; z="1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if h := tt.l.Header(); h != tt.want {
+				t.Errorf("Link.Header() expected '%v', got '%v'", tt.want, h)
+			}
+		})
+	}
+}
+
+func Test_LinkSet_HeaderRoundTrip(t *testing.T) {
+	s := LinkSet{}
+	s.Add("item", Link{Context: "http://localhost/", HRef: "/1", Rel: "item", Title: "One, \"first\""})
+	s.Add("item", Link{Context: "http://localhost/", HRef: "/2", Rel: "item", TitleStar: "Zwei – zweite", Deprecation: "http://localhost/d"})
+	s.Add("alternate", Link{Context: "http://localhost/", Anchor: "#x", HRef: "/a.csv", Rel: "alternate", Type: "text/csv", Media: "screen", Extensions: map[string][]string{"foo": []string{"bar", "baz"}}})
+
+	p, err := ParseHeaderSet("http://localhost/", s.Header())
+	if err != nil {
+		t.Fatalf("ParseHeaderSet() errored with %v when it shouldnt have", err)
+	}
+
+	if !reflect.DeepEqual(p, s) {
+		t.Errorf("ParseHeaderSet() expected %v, got %v", s, p)
+	}
+}
+
+func Test_Registry(t *testing.T) {
+	tests := []struct {
+		name       string
+		rel        string
+		registered bool
+		extension  bool
+		normalized string
+	}{
+		{"registered", "edit-form", true, false, "edit-form"},
+		{"registered mixed case", "DescribedBy", true, false, "describedby"},
+		{"extension uri", "http://example.org/Rels/Foo", false, true, "http://example.org/Rels/Foo"},
+		{"curie", "ea:Order", false, true, "ea:Order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if IsRegistered(tt.rel) != tt.registered {
+				t.Errorf("IsRegistered(%q) expected %v", tt.rel, tt.registered)
+			}
+
+			if IsExtension(tt.rel) != tt.extension {
+				t.Errorf("IsExtension(%q) expected %v", tt.rel, tt.extension)
+			}
+
+			if n := NormalizeRel(tt.rel); n != tt.normalized {
+				t.Errorf("NormalizeRel(%q) expected '%v', got '%v'", tt.rel, tt.normalized, n)
+			}
+		})
+	}
+
+	s := LinkSet{}
+	s.Add("Next", Link{HRef: "/2", Rel: "Next"})
+	s.Add("http://example.org/Rels/Foo", Link{HRef: "/foo"})
+	if !s.Has("next") || len(s.Get("NEXT")) != 1 {
+		t.Errorf("LinkSet should match registered rels case-insensitively")
+	}
+	if s.Has("http://example.org/rels/foo") {
+		t.Errorf("LinkSet should match extension rels exactly")
+	}
+
+	if d, ok := Describe("edit-form"); !ok || d == "" {
+		t.Errorf("Describe() should know registered rels")
+	}
+
+	// Lookups are global, leave them as they were for the other tests.
+	defer func(l []DocLookup) {
+		docMu.Lock()
+		docLookups = l
+		docMu.Unlock()
+	}(docLookups)
+
+	RegisterDocLookup(func(rel string) (string, bool) {
+		if rel == "ea:Order" {
+			return "An order placed by the customer.", true
+		}
+
+		return "", false
+	})
+
+	if d, _ := Describe("ea:Order"); d != "An order placed by the customer." {
+		t.Errorf("Describe() should consult registered lookups, got '%v'", d)
+	}
+}
@@ -0,0 +1,109 @@
+package link
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+/*
+Relation is a link relation type registered with IANA. */
+type Relation struct {
+	Name        string
+	Description string
+}
+
+/*
+DocLookup returns documentation for a relation type, it reports false when it
+knows nothing about the rel. */
+type DocLookup func(rel string) (string, bool)
+
+var (
+	docMu      sync.RWMutex
+	docLookups []DocLookup
+)
+
+/*
+IsRegistered reports whether rel is a registered relation type. Registered
+names are compared case-insensitively. */
+func IsRegistered(rel string) bool {
+	_, ok := registry[strings.ToLower(rel)]
+
+	return ok
+}
+
+/*
+IsExtension reports whether rel is an extension relation type, which is an
+absolute URI. A HAL CURIE like "ea:order" looks like one too. */
+func IsExtension(rel string) bool {
+	u, err := url.Parse(rel)
+
+	return err == nil && u.Scheme != "" && !IsRegistered(rel)
+}
+
+/*
+LookupRelation returns the registered relation type rel. */
+func LookupRelation(rel string) (Relation, bool) {
+	r, ok := registry[strings.ToLower(rel)]
+
+	return r, ok
+}
+
+/*
+NormalizeRel returns the form of rel used as key in a LinkSet: registered
+names are lower cased, everything else, like extension URIs, is kept as is. */
+func NormalizeRel(rel string) string {
+	if IsRegistered(rel) {
+		return strings.ToLower(rel)
+	}
+
+	return rel
+}
+
+/*
+EqualRel reports whether a and b are the same relation type. */
+func EqualRel(a string, b string) bool {
+	return NormalizeRel(a) == NormalizeRel(b)
+}
+
+/*
+RegisterDocLookup adds a documentation source consulted by Describe, for
+example one that resolves CURIEs or the extension relations of an API. Lookups
+are consulted in the order they were registered, before the IANA registry. */
+func RegisterDocLookup(f DocLookup) {
+	docMu.Lock()
+	defer docMu.Unlock()
+
+	docLookups = append(docLookups, f)
+}
+
+/*
+Describe returns what rel means, from the registered documentation lookups or
+the IANA registry. */
+func Describe(rel string) (string, bool) {
+	docMu.RLock()
+	defer docMu.RUnlock()
+
+	for _, f := range docLookups {
+		if d, ok := f(rel); ok {
+			return d, true
+		}
+	}
+
+	if r, ok := LookupRelation(rel); ok {
+		return r.Description, true
+	}
+
+	return "", false
+}
+
+/*
+Registered returns every registered relation type. */
+func Registered() []Relation {
+	l := make([]Relation, 0, len(registry))
+	for _, r := range registry {
+		l = append(l, r)
+	}
+
+	return l
+}
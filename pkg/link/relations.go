@@ -0,0 +1,136 @@
+package link
+
+// Code below lists the link relation types registered with IANA, see
+// https://www.iana.org/assignments/link-relations/link-relations.xhtml
+
+// registry holds the registered relation types, keyed by lower cased name.
+var registry = map[string]Relation{
+	"about":                     {Name: "about", Description: "Refers to a resource that is the subject of the link's context."},
+	"acl":                       {Name: "acl", Description: "Asserts that the link target provides an access control description for the link context."},
+	"alternate":                 {Name: "alternate", Description: "Refers to a substitute for this context."},
+	"amphtml":                   {Name: "amphtml", Description: "Used to reference alternative content that uses the AMP profile of the HTML format."},
+	"api-catalog":               {Name: "api-catalog", Description: "Refers to a list of APIs available from the publisher of the link context."},
+	"appendix":                  {Name: "appendix", Description: "Refers to an appendix."},
+	"apple-touch-icon":          {Name: "apple-touch-icon", Description: "Refers to an icon for the context, synonym for icon."},
+	"apple-touch-startup-image": {Name: "apple-touch-startup-image", Description: "Refers to a launch screen for the context."},
+	"archives":                  {Name: "archives", Description: "Refers to a collection of records, documents, or other materials of historical interest."},
+	"author":                    {Name: "author", Description: "Refers to the context's author."},
+	"blocked-by":                {Name: "blocked-by", Description: "Identifies the entity that blocks access to a resource following receipt of a legal demand."},
+	"bookmark":                  {Name: "bookmark", Description: "Gives a permanent link to use for bookmarking purposes."},
+	"c2pa-manifest":             {Name: "c2pa-manifest", Description: "Refers to a C2PA Manifest associated with the link context."},
+	"canonical":                 {Name: "canonical", Description: "Designates the preferred version of a resource (the IRI and its contents)."},
+	"chapter":                   {Name: "chapter", Description: "Refers to a chapter in a collection of resources."},
+	"cite-as":                   {Name: "cite-as", Description: "Indicates that the link target is preferred over the link context for the purpose of permanent citation."},
+	"collection":                {Name: "collection", Description: "The target IRI points to a resource which represents the collection resource for the context IRI."},
+	"compression-dictionary":    {Name: "compression-dictionary", Description: "Refers to a compression dictionary used for content encoding."},
+	"contents":                  {Name: "contents", Description: "Refers to a table of contents."},
+	"convertedfrom":             {Name: "convertedfrom", Description: "The document linked to was later converted to the document that contains this link relation."},
+	"copyright":                 {Name: "copyright", Description: "Refers to a copyright statement that applies to the link's context."},
+	"create-form":               {Name: "create-form", Description: "The target IRI points to a resource where a submission form can be obtained."},
+	"current":                   {Name: "current", Description: "Refers to a resource containing the most recent item(s) in a collection of resources."},
+	"deprecation":               {Name: "deprecation", Description: "Links to a resource that contains deprecation information about the link's context."},
+	"describedby":               {Name: "describedby", Description: "Refers to a resource providing information about the link's context."},
+	"describes":                 {Name: "describes", Description: "The relationship A 'describes' B asserts that resource A provides a description of resource B."},
+	"disclosure":                {Name: "disclosure", Description: "Refers to a list of patent disclosures made with respect to material for which 'disclosure' relation is specified."},
+	"dns-prefetch":              {Name: "dns-prefetch", Description: "Used to indicate an origin that will be used to fetch required resources for the link context."},
+	"duplicate":                 {Name: "duplicate", Description: "Refers to a resource whose available representations are byte-for-byte identical with the corresponding representations of the context IRI."},
+	"edit":                      {Name: "edit", Description: "Refers to a resource that can be used to edit the link's context."},
+	"edit-form":                 {Name: "edit-form", Description: "The target IRI points to a resource where a submission form for editing associated resource can be obtained."},
+	"edit-media":                {Name: "edit-media", Description: "Refers to a resource that can be used to edit media associated with the link's context."},
+	"enclosure":                 {Name: "enclosure", Description: "Identifies a related resource that is potentially large and might require special handling."},
+	"external":                  {Name: "external", Description: "Refers to a resource that is not part of the same site as the current context."},
+	"first":                     {Name: "first", Description: "An IRI that refers to the furthest preceding resource in a series of resources."},
+	"geofeed":                   {Name: "geofeed", Description: "Refers to a geofeed file for the context."},
+	"glossary":                  {Name: "glossary", Description: "Refers to a glossary of terms."},
+	"help":                      {Name: "help", Description: "Refers to context-sensitive help."},
+	"hosts":                     {Name: "hosts", Description: "Refers to a resource hosted by the server indicated by the link context."},
+	"hub":                       {Name: "hub", Description: "Refers to a hub that enables registration for notification of updates to the context."},
+	"ice-server":                {Name: "ice-server", Description: "Conveys the STUN and TURN servers that can be used by an ICE Agent."},
+	"icon":                      {Name: "icon", Description: "Refers to an icon representing the link's context."},
+	"index":                     {Name: "index", Description: "Refers to an index."},
+	"intervalafter":             {Name: "intervalafter", Description: "Refers to a resource associated with a time interval that ends before the beginning of the time interval associated with the context resource."},
+	"intervalbefore":            {Name: "intervalbefore", Description: "Refers to a resource associated with a time interval that begins after the end of the time interval associated with the context resource."},
+	"intervalcontains":          {Name: "intervalcontains", Description: "Refers to a resource associated with a time interval that begins after the beginning of the time interval associated with the context resource, and ends before its end."},
+	"intervaldisjoint":          {Name: "intervaldisjoint", Description: "Refers to a resource associated with a time interval that begins after the end of, or ends before the beginning of, the time interval associated with the context resource."},
+	"intervalduring":            {Name: "intervalduring", Description: "Refers to a resource associated with a time interval that begins before the beginning of the time interval associated with the context resource, and ends after its end."},
+	"intervalequals":            {Name: "intervalequals", Description: "Refers to a resource associated with a time interval whose beginning and end coincide with those of the time interval associated with the context resource."},
+	"intervalfinishedby":        {Name: "intervalfinishedby", Description: "Refers to a resource associated with a time interval that begins after the beginning of the time interval associated with the context resource, and whose end coincides with its end."},
+	"intervalfinishes":          {Name: "intervalfinishes", Description: "Refers to a resource associated with a time interval that begins before the beginning of the time interval associated with the context resource, and whose end coincides with its end."},
+	"intervalin":                {Name: "intervalin", Description: "Refers to a resource associated with a time interval that begins before or coincides with the beginning of the time interval associated with the context resource, and ends after or coincides with its end."},
+	"intervalmeets":             {Name: "intervalmeets", Description: "Refers to a resource associated with a time interval whose beginning coincides with the end of the time interval associated with the context resource."},
+	"intervalmetby":             {Name: "intervalmetby", Description: "Refers to a resource associated with a time interval whose end coincides with the beginning of the time interval associated with the context resource."},
+	"intervaloverlappedby":      {Name: "intervaloverlappedby", Description: "Refers to a resource associated with a time interval that begins before the beginning of the time interval associated with the context resource, and ends after its beginning but before its end."},
+	"intervaloverlaps":          {Name: "intervaloverlaps", Description: "Refers to a resource associated with a time interval that begins after the beginning of the time interval associated with the context resource, and ends after its end."},
+	"intervalstartedby":         {Name: "intervalstartedby", Description: "Refers to a resource associated with a time interval whose beginning coincides with the beginning of the time interval associated with the context resource, and ends before its end."},
+	"intervalstarts":            {Name: "intervalstarts", Description: "Refers to a resource associated with a time interval whose beginning coincides with the beginning of the time interval associated with the context resource, and ends after its end."},
+	"item":                      {Name: "item", Description: "The target IRI points to a resource that is a member of the collection represented by the context IRI."},
+	"last":                      {Name: "last", Description: "An IRI that refers to the furthest following resource in a series of resources."},
+	"latest-version":            {Name: "latest-version", Description: "Points to a resource containing the latest (e.g., current) version of the context."},
+	"license":                   {Name: "license", Description: "Refers to a license associated with this context."},
+	"linkset":                   {Name: "linkset", Description: "The link target of a link with the \"linkset\" relation type provides a set of links, including links in which the link context of the link participates."},
+	"lrdd":                      {Name: "lrdd", Description: "Refers to further information about the link's context, expressed as a LRDD (\"Link-based Resource Descriptor Document\") resource."},
+	"manifest":                  {Name: "manifest", Description: "Links to a manifest file for the context."},
+	"mask-icon":                 {Name: "mask-icon", Description: "Refers to a mask that can be applied to the icon for the context."},
+	"me":                        {Name: "me", Description: "Indicates that the current resource is represented by the linked resource."},
+	"media-feed":                {Name: "media-feed", Description: "Refers to a feed of personalised media recommendations relevant to the link context."},
+	"memento":                   {Name: "memento", Description: "The Target IRI points to a Memento, a fixed resource that will not change state anymore."},
+	"micropub":                  {Name: "micropub", Description: "Links to the context's Micropub endpoint."},
+	"modulepreload":             {Name: "modulepreload", Description: "Refers to a module that the user agent is to preemptively fetch and store for use in the current context."},
+	"monitor":                   {Name: "monitor", Description: "Refers to a resource that can be used to monitor changes in an HTTP resource."},
+	"monitor-group":             {Name: "monitor-group", Description: "Refers to a resource that can be used to monitor changes in a specified group of HTTP resources."},
+	"next":                      {Name: "next", Description: "Indicates that the link's context is a part of a series, and that the next in the series is the link target."},
+	"next-archive":              {Name: "next-archive", Description: "Refers to the immediately following archive resource."},
+	"nofollow":                  {Name: "nofollow", Description: "Indicates that the context's original author or publisher does not endorse the link target."},
+	"noopener":                  {Name: "noopener", Description: "Indicates that any newly created top-level browsing context which results from following the link will not be an auxiliary browsing context."},
+	"noreferrer":                {Name: "noreferrer", Description: "Indicates that no referrer information is to be leaked when following the link."},
+	"opener":                    {Name: "opener", Description: "Indicates that any newly created top-level browsing context which results from following the link will be an auxiliary browsing context."},
+	"openid2.local_id":          {Name: "openid2.local_id", Description: "Refers to an OpenID Authentication server on which the context relies for an assertion that the end user controls an Identifier."},
+	"openid2.provider":          {Name: "openid2.provider", Description: "Refers to a resource which accepts OpenID Authentication protocol messages for the context."},
+	"original":                  {Name: "original", Description: "The Target IRI points to an Original Resource."},
+	"p3pv1":                     {Name: "P3Pv1", Description: "Refers to a P3P privacy policy for the context."},
+	"payment":                   {Name: "payment", Description: "Indicates a resource where payment is accepted."},
+	"pingback":                  {Name: "pingback", Description: "Gives the address of the pingback resource for the link context."},
+	"preconnect":                {Name: "preconnect", Description: "Used to indicate an origin that will be used to fetch required resources for the link context."},
+	"predecessor-version":       {Name: "predecessor-version", Description: "Points to a resource containing the predecessor version in the version history."},
+	"prefetch":                  {Name: "prefetch", Description: "The prefetch link relation type is used to identify a resource that might be required by the next navigation from the link context."},
+	"preload":                   {Name: "preload", Description: "Refers to a resource that should be loaded early in the processing of the link's context, without blocking rendering."},
+	"prerender":                 {Name: "prerender", Description: "Used to identify a resource that might be required by the next navigation from the link context, and that the user agent ought to fetch and execute."},
+	"prev":                      {Name: "prev", Description: "Indicates that the link's context is a part of a series, and that the previous in the series is the link target."},
+	"preview":                   {Name: "preview", Description: "Refers to a resource that provides a preview of the link's context."},
+	"previous":                  {Name: "previous", Description: "Refers to the previous resource in an ordered series of resources, synonym for prev."},
+	"prev-archive":              {Name: "prev-archive", Description: "Refers to the immediately preceding archive resource."},
+	"privacy-policy":            {Name: "privacy-policy", Description: "Refers to a privacy policy associated with the link's context."},
+	"profile":                   {Name: "profile", Description: "Identifying that a resource representation conforms to a certain profile, without affecting the non-profile semantics of the resource representation."},
+	"publication":               {Name: "publication", Description: "Links to a publication manifest."},
+	"related":                   {Name: "related", Description: "Identifies a related resource."},
+	"restconf":                  {Name: "restconf", Description: "Identifies the root of RESTCONF API as configured on this HTTP server."},
+	"replies":                   {Name: "replies", Description: "Identifies a resource that is a reply to the context of the link."},
+	"ruleinput":                 {Name: "ruleinput", Description: "The resource identified by the link target provides an input value to an instance of a rule."},
+	"search":                    {Name: "search", Description: "Refers to a resource that can be used to search through the link's context and related resources."},
+	"section":                   {Name: "section", Description: "Refers to a section in a collection of resources."},
+	"self":                      {Name: "self", Description: "Conveys an identifier for the link's context."},
+	"service":                   {Name: "service", Description: "Indicates a URI that can be used to retrieve a service document."},
+	"service-desc":              {Name: "service-desc", Description: "Identifies service description for the context that is primarily intended for consumption by machines."},
+	"service-doc":               {Name: "service-doc", Description: "Identifies service documentation for the context that is primarily intended for human consumption."},
+	"service-meta":              {Name: "service-meta", Description: "Identifies general metadata for the context that is primarily intended for consumption by machines."},
+	"sip-trunking-capability":   {Name: "sip-trunking-capability", Description: "Refers to a capability set document that defines parameters or configuration requirements for SIP trunking."},
+	"sponsored":                 {Name: "sponsored", Description: "Refers to a resource that is within a context that is sponsored (such as advertising or another compensation agreement)."},
+	"start":                     {Name: "start", Description: "Refers to the first resource in a collection of resources."},
+	"status":                    {Name: "status", Description: "Identifies a resource that represents the context's status."},
+	"stylesheet":                {Name: "stylesheet", Description: "Refers to a stylesheet."},
+	"subsection":                {Name: "subsection", Description: "Refers to a resource serving as a subsection in a collection of resources."},
+	"successor-version":         {Name: "successor-version", Description: "Points to a resource containing the successor version in the version history."},
+	"sunset":                    {Name: "sunset", Description: "Identifies a resource that provides information about the context's retirement schedule."},
+	"tag":                       {Name: "tag", Description: "Gives a tag (identified by the given address) that applies to the current document."},
+	"terms-of-service":          {Name: "terms-of-service", Description: "Refers to the terms of service associated with the link's context."},
+	"timegate":                  {Name: "timegate", Description: "The Target IRI points to a TimeGate for an Original Resource."},
+	"timemap":                   {Name: "timemap", Description: "The Target IRI points to a TimeMap for an Original Resource."},
+	"type":                      {Name: "type", Description: "Refers to a resource identifying the abstract semantic type of which the link's context is considered to be an instance."},
+	"ugc":                       {Name: "ugc", Description: "Refers to a resource within a context that is user-generated (such as blog comments)."},
+	"up":                        {Name: "up", Description: "Refers to a parent document in a hierarchy of documents."},
+	"version-history":           {Name: "version-history", Description: "Points to a resource containing the version history for the context."},
+	"via":                       {Name: "via", Description: "Identifies a resource that is the source of the information in the link's context."},
+	"webmention":                {Name: "webmention", Description: "Identifies a target URI that supports the Webmention protocol."},
+	"working-copy":              {Name: "working-copy", Description: "Points to a working copy for this resource."},
+	"working-copy-of":           {Name: "working-copy-of", Description: "Points to the versioned resource from which this working copy was obtained."},
+}
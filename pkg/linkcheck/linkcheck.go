@@ -0,0 +1,314 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/identbase/getting/pkg/crawl"
+	"github.com/identbase/getting/pkg/resource"
+	"github.com/identbase/getting/pkg/resource/representor"
+)
+
+/*
+Kind is the reason a link is broken. */
+type Kind string
+
+const (
+	// BadStatus is a link whose target responds with a status that is not
+	// a success.
+	BadStatus Kind = "bad-status"
+	// UnsupportedContentType is a link whose target has a content-type no
+	// representor understands.
+	UnsupportedContentType Kind = "unsupported-content-type"
+	// InvalidHRef is a link whose href cannot be parsed or resolved.
+	InvalidHRef Kind = "invalid-href"
+	// TemplateExpansion is a templated link that does not expand to a uri
+	// with the sample variables.
+	TemplateExpansion Kind = "template-expansion"
+	// FetchError is a link whose target cannot be fetched at all, for
+	// example because the connection is refused.
+	FetchError Kind = "fetch-error"
+)
+
+/*
+Problem is a broken link. */
+type Problem struct {
+	Kind Kind
+	// From is the uri of the resource the link was found in, empty when
+	// the bookmark itself is broken.
+	From string
+	Rel  string
+	HRef string
+	// URI is the resolved, or expanded, target of the link when there is
+	// one.
+	URI string
+	// StatusCode is the status of the target for BadStatus problems.
+	StatusCode int
+	Message    string
+}
+
+/*
+Report is the result of a check. */
+type Report struct {
+	// Root is the uri of the bookmark the check started at.
+	Root string
+	// Checked is the number of links that were checked.
+	Checked  int
+	Problems []Problem
+}
+
+/*
+OK returns true when no broken link was found. */
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+/*
+Checker validates every link reachable from the bookmark of a client. */
+type Checker struct {
+	client       resource.Getting
+	crawl        []crawl.Option
+	samples      map[string]string
+	sample       string
+	skipExternal bool
+}
+
+/*
+Option configures a Checker object. */
+type Option func(*Checker)
+
+/*
+WithCrawlOptions sets the options of the crawl the check is built on, to
+limit its depth or the hosts it visits for example. */
+func WithCrawlOptions(opts ...crawl.Option) Option {
+	return func(c *Checker) {
+		c.crawl = append(c.crawl, opts...)
+	}
+}
+
+/*
+WithSamples sets the values used for template variables, by variable name. */
+func WithSamples(v map[string]string) Option {
+	return func(c *Checker) {
+		for k, s := range v {
+			c.samples[k] = s
+		}
+	}
+}
+
+/*
+WithDefaultSample sets the value used for template variables that have no
+sample of their own, "sample" by default. */
+func WithDefaultSample(s string) Option {
+	return func(c *Checker) {
+		c.sample = s
+	}
+}
+
+/*
+SkipExternal does not request the targets on hosts that are not crawled, only
+their hrefs are checked. Use it when the check runs without network access. */
+func SkipExternal() Option {
+	return func(c *Checker) {
+		c.skipExternal = true
+	}
+}
+
+/*
+New creates a new Checker using client g. */
+func New(g resource.Getting, opts ...Option) *Checker {
+	c := Checker{
+		client:  g,
+		crawl:   []crawl.Option{},
+		samples: map[string]string{},
+		sample:  "sample",
+	}
+
+	for _, o := range opts {
+		o(&c)
+	}
+
+	return &c
+}
+
+/*
+Check crawls the API of client g and reports its broken links. */
+func Check(ctx context.Context, g resource.Getting, opts ...Option) (*Report, error) {
+	return New(g, opts...).Run(ctx)
+}
+
+/*
+Run crawls the API and reports its broken links. Targets the crawl did not
+fetch, on hosts that are not crawled or beyond its depth, are requested once,
+with HEAD or else GET, and are only broken when they do not respond with a
+success: they do not need to be in a format getting understands. */
+func (c *Checker) Run(ctx context.Context) (*Report, error) {
+	g, err := crawl.New(c.client, c.crawl...).Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := Report{
+		Root:     g.Root,
+		Problems: []Problem{},
+	}
+
+	if n := g.Nodes[g.Root]; n != nil && n.Err != nil {
+		p := classify(n.Err)
+		p.URI = n.URI
+		r.Problems = append(r.Problems, p)
+	}
+
+	probed := map[string]error{}
+
+	for _, e := range g.SortedEdges() {
+		r.Checked++
+
+		switch {
+		case e.Err != nil:
+			r.Problems = append(r.Problems, Problem{
+				Kind:    InvalidHRef,
+				From:    e.From,
+				Rel:     e.Rel,
+				HRef:    e.Link.HRef,
+				Message: e.Err.Error(),
+			})
+		case e.Link.Templated:
+			if p, ok := c.expand(e); !ok {
+				r.Problems = append(r.Problems, p)
+			}
+		default:
+			n := g.Nodes[e.To]
+			if n == nil {
+				continue
+			}
+
+			err := n.Err
+			if !n.Fetched && !(n.External && c.skipExternal) {
+				var ok bool
+				if err, ok = probed[e.To]; !ok {
+					err = c.probe(ctx, e.To)
+					probed[e.To] = err
+				}
+			}
+
+			if err == nil {
+				continue
+			}
+
+			p := classify(err)
+			p.From = e.From
+			p.Rel = e.Rel
+			p.HRef = e.Link.HRef
+			p.URI = e.To
+			r.Problems = append(r.Problems, p)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(r.Problems, func(i, j int) bool {
+		return r.Problems[i].From < r.Problems[j].From
+	})
+
+	return &r, nil
+}
+
+/*
+probe requests uri u, that the crawl did not fetch, and returns why it is
+broken, nil when it is not. Servers that do not implement HEAD are sent a GET. */
+func (c *Checker) probe(ctx context.Context, u string) error {
+	for _, m := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequest(m, u, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if m == "HEAD" && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return &resource.ResponseError{
+				Method:     m,
+				URI:        u,
+				StatusCode: resp.StatusCode,
+			}
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+/*
+expand expands the templated link of edge e with the sample variables, it
+returns false and the problem when that fails. */
+func (c *Checker) expand(e crawl.Edge) (Problem, bool) {
+	p := Problem{
+		Kind: TemplateExpansion,
+		From: e.From,
+		Rel:  e.Rel,
+		HRef: e.Link.HRef,
+	}
+
+	vn, err := e.Link.Variables()
+	if err != nil {
+		p.Message = err.Error()
+		return p, false
+	}
+
+	v := map[string]string{}
+	for _, n := range vn {
+		s, ok := c.samples[n]
+		if !ok {
+			s = c.sample
+		}
+
+		v[n] = s
+	}
+
+	u, err := e.Link.Expand(v)
+	if err == nil {
+		_, err = url.Parse(u)
+	}
+	if err != nil {
+		p.Message = err.Error()
+		return p, false
+	}
+
+	return Problem{}, true
+}
+
+/*
+classify returns the problem that err, returned when fetching a link
+target, stands for. */
+func classify(err error) Problem {
+	p := Problem{
+		Kind:    FetchError,
+		Message: err.Error(),
+	}
+
+	var re *resource.ResponseError
+	switch {
+	case errors.As(err, &re):
+		p.Kind = BadStatus
+		p.StatusCode = re.StatusCode
+	case errors.Is(err, representor.ErrUnsupportedContentType), errors.Is(err, representor.ErrMissingContentType):
+		p.Kind = UnsupportedContentType
+	}
+
+	return p
+}
@@ -0,0 +1,258 @@
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/identbase/getting"
+	"github.com/identbase/getting/pkg/crawl"
+)
+
+/*
+newExternal returns a server on another host, whose resources are not in a
+format getting understands. */
+func newExternal() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set("Content-Type", "text/html")
+		case "/nohead":
+			if r.Method == "HEAD" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newServer(ext string) *httptest.Server {
+	pages := map[string]string{
+		"/": `{"_links": {
+			"self": {"href": "/"},
+			"articles": {"href": "/articles"},
+			"missing": {"href": "/missing"},
+			"export": {"href": "/export.txt"},
+			"broken": {"href": "http://[::1"},
+			"search": {"href": "/search{?q}", "templated": true},
+			"find": {"href": "/find{?q", "templated": true},
+			"elsewhere": {"href": "` + ext + `/ok"},
+			"moved": {"href": "` + ext + `/gone"},
+			"legacy": {"href": "` + ext + `/nohead"}
+		}}`,
+		"/articles": `{"_links": {"self": {"href": "/articles"}, "up": {"href": "/"}, "gone": {"href": "/missing"}}}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/export.txt" {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello"))
+			return
+		}
+
+		p, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(p))
+	}))
+}
+
+func Test_Checker_Run(t *testing.T) {
+	ext := newExternal()
+	defer ext.Close()
+
+	s := newServer(ext.URL)
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]Kind
+	}{
+		{
+			"success external targets",
+			nil,
+			map[string]Kind{
+				"missing": BadStatus,
+				"gone":    BadStatus,
+				"moved":   BadStatus,
+				"export":  UnsupportedContentType,
+				"broken":  InvalidHRef,
+				"find":    TemplateExpansion,
+			},
+		},
+		{
+			"success skip external targets",
+			[]Option{SkipExternal()},
+			map[string]Kind{
+				"missing": BadStatus,
+				"gone":    BadStatus,
+				"export":  UnsupportedContentType,
+				"broken":  InvalidHRef,
+				"find":    TemplateExpansion,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := getting.New(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := Check(context.Background(), g, tt.opts...)
+			if err != nil {
+				t.Fatalf("Check() errored with %v when it shouldnt have", err)
+			}
+
+			if r.OK() {
+				t.Fatal("Report.OK() should be false when links are broken")
+			}
+
+			if len(r.Problems) != len(tt.want) {
+				t.Errorf("Check() expected %d problems, got %v", len(tt.want), r.Problems)
+			}
+
+			for _, p := range r.Problems {
+				k, ok := tt.want[p.Rel]
+				if !ok {
+					t.Errorf("Check() should not report %v", p)
+					continue
+				}
+
+				if p.Kind != k {
+					t.Errorf("Check() rel %s expected %s, got %s", p.Rel, k, p.Kind)
+				}
+
+				if p.Kind == BadStatus && p.StatusCode != http.StatusNotFound {
+					t.Errorf("Check() rel %s expected status 404, got %d", p.Rel, p.StatusCode)
+				}
+			}
+
+			if r.Checked != 13 {
+				t.Errorf("Check() expected 13 links checked, got %d", r.Checked)
+			}
+		})
+	}
+}
+
+func Test_Checker_Run_Depth(t *testing.T) {
+	pages := map[string]string{
+		"/":  `{"_links": {"self": {"href": "/"}, "a": {"href": "/a"}}}`,
+		"/a": `{"_links": {"self": {"href": "/a"}, "missing": {"href": "/missing"}, "b": {"href": "/b"}}}`,
+		"/b": `{"_links": {"self": {"href": "/b"}}}`,
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(p))
+	}))
+	defer s.Close()
+
+	g, err := getting.New(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Check(context.Background(), g, WithCrawlOptions(crawl.MaxDepth(1)))
+	if err != nil {
+		t.Fatalf("Check() errored with %v when it shouldnt have", err)
+	}
+
+	if len(r.Problems) != 1 || r.Problems[0].Rel != "missing" || r.Problems[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Check() should report the links beyond the depth of the crawl, got %v", r.Problems)
+	}
+
+	if r.Checked != 5 {
+		t.Errorf("Check() expected 5 links checked, got %d", r.Checked)
+	}
+}
+
+func Test_Checker_Run_Root(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+
+	g, err := getting.New(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Check(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Check() errored with %v when it shouldnt have", err)
+	}
+
+	if len(r.Problems) != 1 || r.Problems[0].Kind != BadStatus || r.Problems[0].From != "" {
+		t.Errorf("Check() should report the bookmark, got %v", r.Problems)
+	}
+}
+
+func Test_Report_Write(t *testing.T) {
+	r := Report{
+		Root:    "http://example.com/",
+		Checked: 3,
+		Problems: []Problem{
+			{Kind: BadStatus, From: "http://example.com/", Rel: "item", HRef: "/a", URI: "http://example.com/a", StatusCode: 404, Message: "GET http://example.com/a: 404 Not Found"},
+		},
+	}
+
+	t.Run("success json", func(t *testing.T) {
+		b := bytes.Buffer{}
+		if err := r.WriteJSON(&b); err != nil {
+			t.Fatalf("Report.WriteJSON() errored with %v when it shouldnt have", err)
+		}
+
+		v := struct {
+			Checked  int
+			OK       bool
+			Problems []map[string]interface{}
+		}{}
+		if err := json.Unmarshal(b.Bytes(), &v); err != nil {
+			t.Fatal(err)
+		}
+
+		if v.Checked != 3 || v.OK || len(v.Problems) != 1 || v.Problems[0]["status"] != float64(404) {
+			t.Errorf("Report.WriteJSON() should write the problems, got %s", b.String())
+		}
+	})
+
+	t.Run("success junit", func(t *testing.T) {
+		b := bytes.Buffer{}
+		if err := r.WriteJUnit(&b); err != nil {
+			t.Fatalf("Report.WriteJUnit() errored with %v when it shouldnt have", err)
+		}
+
+		if !strings.HasPrefix(b.String(), "<?xml") {
+			t.Errorf("Report.WriteJUnit() should write an xml header, got %s", b.String())
+		}
+
+		v := junitSuite{}
+		if err := xml.Unmarshal(b.Bytes(), &v); err != nil {
+			t.Fatal(err)
+		}
+
+		if v.Tests != 3 || v.Failures != 1 || len(v.Cases) != 1 || v.Cases[0].Failure.Type != string(BadStatus) {
+			t.Errorf("Report.WriteJUnit() should write a failing case, got %s", b.String())
+		}
+	})
+}
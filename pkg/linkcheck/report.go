@@ -0,0 +1,115 @@
+package linkcheck
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+/*
+jsonProblem is a problem of the JSON report. */
+type jsonProblem struct {
+	Kind       Kind   `json:"kind"`
+	From       string `json:"from,omitempty"`
+	Rel        string `json:"rel,omitempty"`
+	HRef       string `json:"href,omitempty"`
+	URI        string `json:"uri,omitempty"`
+	StatusCode int    `json:"status,omitempty"`
+	Message    string `json:"message"`
+}
+
+/*
+WriteJSON writes the report as a JSON document. */
+func (r *Report) WriteJSON(w io.Writer) error {
+	p := make([]jsonProblem, 0, len(r.Problems))
+	for _, v := range r.Problems {
+		p = append(p, jsonProblem(v))
+	}
+
+	b, err := json.MarshalIndent(map[string]interface{}{
+		"root":     r.Root,
+		"checked":  r.Checked,
+		"ok":       r.OK(),
+		"problems": p,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+/*
+junitSuite is the testsuite element of a JUnit XML report. */
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+/*
+junitCase is a testcase element of a JUnit XML report. */
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+/*
+junitFailure is the failure of a testcase. */
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+/*
+WriteJUnit writes the report as a JUnit XML test suite, with one failed test
+case per broken link, so CI servers can show them. The number of tests is the
+number of links checked. */
+func (r *Report) WriteJUnit(w io.Writer) error {
+	s := junitSuite{
+		Name:     "linkcheck " + r.Root,
+		Tests:    r.Checked,
+		Failures: len(r.Problems),
+		Cases:    []junitCase{},
+	}
+
+	for _, p := range r.Problems {
+		cn := p.From
+		if cn == "" {
+			cn = r.Root
+		}
+
+		n := p.Rel + " " + p.HRef
+		if p.Rel == "" {
+			n = p.URI
+		}
+
+		s.Cases = append(s.Cases, junitCase{
+			Name:      n,
+			ClassName: cn,
+			Failure: &junitFailure{
+				Type:    string(p.Kind),
+				Message: p.Message,
+				Text:    fmt.Sprintf("%s: %s", p.Kind, p.Message),
+			},
+		})
+	}
+
+	b, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
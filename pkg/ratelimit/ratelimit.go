@@ -0,0 +1,229 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Limiter is a token bucket rate limiter. Tokens are added at a fixed rate up to
+the burst size, and every request takes one token. */
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// maxRate and maxBurst are the rate and burst the limiter was created
+	// with, the server can lower them but not raise them.
+	maxRate  float64
+	maxBurst float64
+
+	// adaptive makes the limiter follow the RateLimit-* response headers.
+	adaptive bool
+	// remaining and reset are the quota the server last advertised.
+	remaining int
+	reset     time.Time
+}
+
+/*
+Option configures a Limiter object. */
+type Option func(*Limiter)
+
+/*
+Adaptive makes the limiter follow the `RateLimit-Limit`, `RateLimit-Remaining`
+and `RateLimit-Reset` headers the server sends, on top of the fixed rate. */
+func Adaptive() Option {
+	return func(l *Limiter) {
+		l.adaptive = true
+	}
+}
+
+/*
+New creates a new Limiter allowing r requests per second, with bursts of up to
+b requests. */
+func New(r float64, b int, opts ...Option) *Limiter {
+	if b < 1 {
+		b = 1
+	}
+
+	l := Limiter{
+		rate:      r,
+		burst:     float64(b),
+		tokens:    float64(b),
+		last:      time.Now(),
+		maxRate:   r,
+		maxBurst:  float64(b),
+		remaining: -1,
+	}
+
+	for _, o := range opts {
+		o(&l)
+	}
+
+	return &l
+}
+
+/*
+Wait blocks until a request may be sent, or until the context is done. */
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		d := l.reserve()
+		if d <= 0 {
+			return nil
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+/*
+reserve takes a token if one is available, otherwise it returns how long to
+wait before trying again. */
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	if l.remaining == 0 {
+		if now.Before(l.reset) {
+			return l.reset.Sub(now)
+		}
+
+		// The server window has passed, fall back to the fixed rate until
+		// the next response says otherwise.
+		l.remaining = -1
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		if l.remaining > 0 {
+			l.remaining--
+		}
+
+		return 0
+	}
+
+	if l.rate <= 0 {
+		return time.Second
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+/*
+Update reads the RateLimit-* headers from a response. The quota left is taken
+from `RateLimit-Remaining` and `RateLimit-Reset` (in seconds). `RateLimit-Limit`
+caps the burst at the quota of the window, and when it names the window, like
+"100, 100;w=60", caps the rate at the quota over the window. It does nothing
+unless the limiter is Adaptive. */
+func (l *Limiter) Update(h http.Header) {
+	if !l.adaptive {
+		return
+	}
+
+	if n, w, ok := limitHeader(h.Get("RateLimit-Limit")); ok {
+		l.limit(n, w)
+	}
+
+	rm, err := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	if err != nil || rm < 0 {
+		return
+	}
+
+	rs, err := strconv.Atoi(h.Get("RateLimit-Reset"))
+	if err != nil || rs < 0 {
+		rs = 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.remaining = rm
+	l.reset = time.Now().Add(time.Duration(rs) * time.Second)
+
+	// Never hold more tokens than the server has left for us.
+	if l.tokens > float64(rm) {
+		l.tokens = float64(rm)
+	}
+}
+
+/*
+limit lowers the burst to quota n, and the rate to n requests in window w
+when w is not zero. */
+func (l *Limiter) limit(n int, w time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.burst = l.maxBurst
+	if b := float64(n); b < l.burst {
+		// A burst below one would block every request.
+		l.burst = math.Max(b, 1)
+	}
+
+	l.rate = l.maxRate
+	if w > 0 {
+		if r := float64(n) / w.Seconds(); r < l.rate {
+			l.rate = r
+		}
+	}
+
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+/*
+limitHeader parses a RateLimit-Limit header, like "100" or "100, 100;w=60",
+into the quota and the window it applies to, zero when it is not given. */
+func limitHeader(h string) (int, time.Duration, bool) {
+	if h == "" {
+		return 0, 0, false
+	}
+
+	n, w := -1, 0
+	for _, item := range strings.Split(h, ",") {
+		ps := strings.Split(item, ";")
+
+		v, err := strconv.Atoi(strings.TrimSpace(ps[0]))
+		if err != nil || v < 0 {
+			return 0, 0, false
+		}
+
+		if n < 0 {
+			n = v
+		}
+
+		for _, p := range ps[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) != 2 || kv[0] != "w" || w > 0 {
+				continue
+			}
+
+			if s, err := strconv.Atoi(kv[1]); err == nil && s > 0 {
+				w = s
+			}
+		}
+	}
+
+	return n, time.Duration(w) * time.Second, true
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_Limiter_Wait(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		n     int
+		min   time.Duration
+	}{
+		{
+			"success within burst",
+			1,
+			3,
+			3,
+			0,
+		},
+		{
+			"success waits for tokens",
+			20,
+			1,
+			3,
+			90 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.rate, tt.burst)
+
+			start := time.Now()
+			for i := 0; i < tt.n; i++ {
+				if err := l.Wait(context.Background()); err != nil {
+					t.Errorf("Limiter.Wait() should not error, got %v", err)
+				}
+			}
+
+			if d := time.Since(start); d < tt.min {
+				t.Errorf("Limiter.Wait() expected to take at least %v, took %v", tt.min, d)
+			}
+		})
+	}
+}
+
+func Test_Limiter_WaitCancel(t *testing.T) {
+	l := New(0.1, 1)
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Limiter.Wait() expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func Test_Limiter_Update(t *testing.T) {
+	l := New(100, 10, Adaptive())
+
+	h := http.Header{}
+	h.Set("RateLimit-Limit", "10")
+	h.Set("RateLimit-Remaining", "0")
+	h.Set("RateLimit-Reset", "60")
+	l.Update(h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Limiter.Wait() should wait for the server reset, got %v", err)
+	}
+
+	h.Set("RateLimit-Remaining", "5")
+	l.Update(h)
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("Limiter.Wait() should not error, got %v", err)
+	}
+}
+
+func Test_Limiter_UpdateLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit string
+		rate  float64
+		burst float64
+	}{
+		{
+			"success quota caps burst",
+			"5",
+			100,
+			5,
+		},
+		{
+			"success window caps rate",
+			"60, 60;w=60",
+			1,
+			10,
+		},
+		{
+			"success quota above the configuration",
+			"1000;w=1",
+			100,
+			10,
+		},
+		{
+			"success invalid header ignored",
+			"many",
+			100,
+			10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(100, 10, Adaptive())
+
+			h := http.Header{}
+			h.Set("RateLimit-Limit", tt.limit)
+			l.Update(h)
+
+			if l.rate != tt.rate || l.burst != tt.burst {
+				t.Errorf("Limiter.Update() expected rate %v and burst %v, got %v and %v", tt.rate, tt.burst, l.rate, l.burst)
+			}
+		})
+	}
+}
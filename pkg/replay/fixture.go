@@ -0,0 +1,78 @@
+package replay
+
+import (
+	"encoding/base64"
+	"net/http"
+	"unicode/utf8"
+)
+
+/*
+Interaction is a recorded request and the response it got. */
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+/*
+Request is a recorded request. */
+type Request struct {
+	Method string      `json:"method"`
+	URI    string      `json:"uri"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body"`
+}
+
+/*
+Response is a recorded response. */
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body"`
+}
+
+/*
+Body is a recorded message body. Text is kept as is so fixtures can be read
+and edited by hand, anything else is base64 encoded. */
+type Body struct {
+	Data []byte `json:"-"`
+	// Text is the body when it is valid UTF-8.
+	Text string `json:"text,omitempty"`
+	// Base64 is the encoded body otherwise.
+	Base64 string `json:"base64,omitempty"`
+}
+
+/*
+newBody creates a new Body holding b. */
+func newBody(b []byte) Body {
+	d := Body{Data: b}
+	if utf8.Valid(b) {
+		d.Text = string(b)
+	} else {
+		d.Base64 = base64.StdEncoding.EncodeToString(b)
+	}
+
+	return d
+}
+
+/*
+decode sets Data from the Text or Base64 fields, after loading a fixture. */
+func (d *Body) decode() error {
+	if d.Base64 == "" {
+		d.Data = []byte(d.Text)
+		return nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(d.Base64)
+	if err != nil {
+		return err
+	}
+
+	d.Data = b
+	return nil
+}
+
+/*
+fixture is the content of a fixture file. */
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
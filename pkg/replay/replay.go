@@ -0,0 +1,390 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/identbase/getting/pkg/auth"
+)
+
+/*
+ErrNoInteraction is returned in replay mode for a request that was not
+recorded. */
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+/*
+Mode tells a Transport whether to record or to replay. */
+type Mode int
+
+const (
+	// Replay serves the interactions of the fixture file, and never talks
+	// to a server.
+	Replay Mode = iota
+	// Record sends requests to the server and records the interactions,
+	// Save writes them to the fixture file.
+	Record
+	// Auto replays when the fixture file exists and records otherwise.
+	Auto
+)
+
+/*
+Matcher decides whether recorded interaction i answers request r. */
+type Matcher func(r Request, i Interaction) bool
+
+/*
+DefaultMatcher matches on method, uri and body. */
+func DefaultMatcher(r Request, i Interaction) bool {
+	return r.Method == i.Request.Method &&
+		r.URI == i.Request.URI &&
+		bytes.Equal(r.Body.Data, i.Request.Body.Data)
+}
+
+/*
+Transport is an http.RoundTripper that records requests and their responses
+to a fixture file, or replays them from it, so tests can run offline against
+captured traffic. Attach it with getting.WithTransport. */
+type Transport struct {
+	file    string
+	mode    Mode
+	next    http.RoundTripper
+	matcher Matcher
+	redact  map[string]bool
+	// redactQuery holds the query parameters whose values are hidden.
+	redactQuery map[string]bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         map[int]bool
+}
+
+/*
+Option configures a Transport object. */
+type Option func(*Transport)
+
+/*
+WithUpstream sets the RoundTripper requests are recorded from,
+http.DefaultTransport by default. */
+func WithUpstream(rt http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.next = rt
+	}
+}
+
+/*
+WithMatcher sets how requests are matched to recorded interactions. */
+func WithMatcher(m Matcher) Option {
+	return func(t *Transport) {
+		t.matcher = m
+	}
+}
+
+/*
+Redact hides the values of request and response headers h in the fixture.
+Authorization, Cookie and Set-Cookie are always hidden. */
+func Redact(h ...string) Option {
+	return func(t *Transport) {
+		for _, v := range h {
+			t.redact[http.CanonicalHeaderKey(v)] = true
+		}
+	}
+}
+
+/*
+RedactQuery hides the values of query parameters q in the request uris of the
+fixture. Requests are matched on the hidden uri. */
+func RedactQuery(q ...string) Option {
+	return func(t *Transport) {
+		for _, v := range q {
+			t.redactQuery[v] = true
+		}
+	}
+}
+
+/*
+RedactAPIKeys hides the keys of APIKey providers in the fixture, whether they
+are sent as a header or as a query parameter. Keys registered with
+getting.WithAuth are not known to the Transport, they must be given here. */
+func RedactAPIKeys(keys ...*auth.APIKey) Option {
+	return func(t *Transport) {
+		for _, k := range keys {
+			switch k.In {
+			case auth.InHeader:
+				t.redact[http.CanonicalHeaderKey(k.Name)] = true
+			case auth.InQuery:
+				t.redactQuery[k.Name] = true
+			}
+		}
+	}
+}
+
+/*
+New creates a new Transport for fixture file f in mode m. In replay mode the
+fixture is loaded right away. */
+func New(f string, m Mode, opts ...Option) (*Transport, error) {
+	t := Transport{
+		file:    f,
+		mode:    m,
+		next:    http.DefaultTransport,
+		matcher: DefaultMatcher,
+		redact: map[string]bool{
+			"Authorization": true,
+			"Cookie":        true,
+			"Set-Cookie":    true,
+		},
+		redactQuery:  map[string]bool{},
+		interactions: []Interaction{},
+		used:         map[int]bool{},
+	}
+
+	for _, o := range opts {
+		o(&t)
+	}
+
+	if t.mode == Auto {
+		t.mode = Record
+		if _, err := os.Stat(f); err == nil {
+			t.mode = Replay
+		}
+	}
+
+	if t.mode == Replay {
+		if err := t.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &t, nil
+}
+
+/*
+Mode returns whether the Transport records or replays. */
+func (t *Transport) Mode() Mode {
+	return t.mode
+}
+
+/*
+Interactions returns the interactions recorded or loaded so far. */
+func (t *Transport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]Interaction{}, t.interactions...)
+}
+
+/*
+RoundTrip implements http.RoundTripper. */
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r := Request{
+		Method: req.Method,
+		URI:    t.redactedURI(req.URL),
+		Header: t.redacted(req.Header),
+		Body:   newBody(b),
+	}
+
+	if t.mode == Replay {
+		return t.replay(req, r)
+	}
+
+	return t.record(req, r)
+}
+
+/*
+replay answers req with the first unused interaction that matches r. Once
+every match was used the last one is served again, so requests that are
+repeated more often than they were recorded still succeed. */
+func (t *Transport) replay(req *http.Request, r Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found := -1
+	for k, v := range t.interactions {
+		if !t.matcher(r, v) {
+			continue
+		}
+
+		found = k
+		if !t.used[k] {
+			break
+		}
+	}
+
+	if found < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, r.Method, r.URI)
+	}
+
+	t.used[found] = true
+	return response(req, t.interactions[found].Response), nil
+}
+
+/*
+record sends req upstream and records the exchange. */
+func (t *Transport) record(req *http.Request, r Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(r.Body.Data))
+
+	resp, err := t.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	i := Interaction{
+		Request: r,
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: resp.Header.Clone(),
+			Body:   newBody(b),
+		},
+	}
+
+	t.mu.Lock()
+	t.interactions = append(t.interactions, i)
+	t.mu.Unlock()
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return resp, nil
+}
+
+/*
+Save writes the recorded interactions to the fixture file, creating its
+directory when needed. Header values are redacted in the file only, the
+responses handed out while recording are untouched. */
+func (t *Transport) Save() error {
+	t.mu.Lock()
+	f := fixture{Interactions: make([]Interaction, 0, len(t.interactions))}
+	for _, v := range t.interactions {
+		v.Response.Header = t.redacted(v.Response.Header)
+		f.Interactions = append(f.Interactions, v)
+	}
+	t.mu.Unlock()
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.file), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(t.file, append(b, '\n'), 0644)
+}
+
+/*
+load reads the interactions of the fixture file. */
+func (t *Transport) load() error {
+	b, err := ioutil.ReadFile(t.file)
+	if err != nil {
+		return err
+	}
+
+	f := fixture{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+
+	for k := range f.Interactions {
+		if err := f.Interactions[k].Request.Body.decode(); err != nil {
+			return err
+		}
+
+		if err := f.Interactions[k].Response.Body.decode(); err != nil {
+			return err
+		}
+	}
+
+	t.interactions = f.Interactions
+	return nil
+}
+
+/*
+redacted returns a copy of header h with the redacted values hidden. */
+func (t *Transport) redacted(h http.Header) http.Header {
+	c := h.Clone()
+	for k := range c {
+		if t.redact[k] {
+			c[k] = []string{"REDACTED"}
+		}
+	}
+
+	return c
+}
+
+/*
+redactedURI returns uri u with the values of the redacted query parameters
+hidden. The rest of the query is kept as it is. */
+func (t *Transport) redactedURI(u *url.URL) string {
+	if len(t.redactQuery) == 0 || u.RawQuery == "" {
+		return u.String()
+	}
+
+	q := strings.Split(u.RawQuery, "&")
+	for k, v := range q {
+		n := strings.SplitN(v, "=", 2)[0]
+		if un, err := url.QueryUnescape(n); err == nil && t.redactQuery[un] {
+			q[k] = n + "=REDACTED"
+		}
+	}
+
+	c := *u
+	c.RawQuery = strings.Join(q, "&")
+
+	return c.String()
+}
+
+/*
+readBody reads the body of req, and gives req a fresh copy of it. */
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return []byte{}, nil
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+/*
+response rebuilds the recorded response r to request req. */
+func response(req *http.Request, r Response) *http.Response {
+	h := r.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body.Data)),
+		ContentLength: int64(len(r.Body.Data)),
+		Request:       req,
+	}
+}
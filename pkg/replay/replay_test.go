@@ -0,0 +1,171 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/identbase/getting"
+	"github.com/identbase/getting/pkg/auth"
+	"github.com/identbase/getting/pkg/gettingtest"
+	"github.com/identbase/getting/pkg/resource/representor"
+)
+
+func Test_Transport_RecordReplay(t *testing.T) {
+	d, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	f := filepath.Join(d, "fixtures", "articles.json")
+
+	s := gettingtest.NewServer()
+	s.Resource("/").Link("articles", "/articles")
+	s.Resource("/articles").Link("item", "/articles/1")
+	s.Resource("/articles/1").Property("title", "One").Link("author", "/people/1")
+	s.Resource("/people/1").Property("name", "Ada")
+
+	follow := func(g *getting.Getting) (interface{}, error) {
+		return g.Chain().Follow("articles").Follow("item").Follow("author").Get(context.Background())
+	}
+
+	rec, err := New(f, Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Mode() != Record {
+		t.Fatalf("expected record mode without a fixture, got %v", rec.Mode())
+	}
+
+	g := s.Getting(t, "/", getting.WithTransport(rec), getting.WithAuth(s.URL, auth.NewBearer("secret")))
+	if _, err := follow(g); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Close()
+
+	b, err := ioutil.ReadFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(b), "secret") {
+		t.Errorf("expected the bearer token to be redacted, got:\n%s", b)
+	}
+
+	t.Run("success replay", func(t *testing.T) {
+		rep, err := New(f, Auto)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rep.Mode() != Replay {
+			t.Fatalf("expected replay mode with a fixture, got %v", rep.Mode())
+		}
+
+		if len(rep.Interactions()) != 4 {
+			t.Errorf("expected 4 interactions, got %d", len(rep.Interactions()))
+		}
+
+		g, _ := getting.New(s.URL+"/", getting.WithTransport(rep))
+		st, err := follow(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b, ok := st.(representor.HALBody); !ok || b.Properties["name"] != "Ada" {
+			t.Errorf("expected the replayed author, got %v", st)
+		}
+	})
+
+	t.Run("error not recorded", func(t *testing.T) {
+		rep, err := New(f, Replay)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		g, _ := getting.New(s.URL+"/missing", getting.WithTransport(rep))
+		r, _ := g.Go("")
+		if _, err := r.Get(); !errors.Is(err, ErrNoInteraction) {
+			t.Errorf("expected ErrNoInteraction, got %v", err)
+		}
+	})
+}
+
+func Test_Transport_RedactAPIKeys(t *testing.T) {
+	d, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	f := filepath.Join(d, "keys.json")
+
+	s := gettingtest.NewServer()
+	s.Resource("/").Property("title", "Home")
+
+	q := auth.NewAPIKeyQuery("api_key", "query-secret")
+	h := auth.NewAPIKeyHeader("X-API-Key", "header-secret")
+
+	get := func(rt *Transport, a auth.Provider) error {
+		g, err := getting.New(s.URL+"/?page=1", getting.WithTransport(rt), getting.WithAuth(s.URL, a))
+		if err != nil {
+			return err
+		}
+
+		r, _ := g.Go("")
+		_, err = r.Get()
+		return err
+	}
+
+	rec, err := New(f, Record, RedactAPIKeys(q, h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range []auth.Provider{q, h} {
+		if err := get(rec, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Close()
+
+	b, err := ioutil.ReadFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(b), "secret") {
+		t.Errorf("expected the api keys to be redacted, got:\n%s", b)
+	}
+
+	rep, err := New(f, Replay, RedactAPIKeys(q, h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u := rep.Interactions()[0].Request.URI; !strings.HasSuffix(u, "/?page=1&api_key=REDACTED") {
+		t.Errorf("expected the rest of the query to be kept, got %s", u)
+	}
+
+	for _, a := range []auth.Provider{q, h} {
+		if err := get(rep, a); err != nil {
+			t.Errorf("expected the redacted request to replay, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+)
+
+/*
+FollowChain is a lazy sequence of Follow calls. Nothing is fetched until the
+chain is resolved with Resource or Get, and embedded resources are used along
+the way when the server sent them. */
+type FollowChain struct {
+	client Getting
+	start  string
+	hops   []hop
+}
+
+type hop struct {
+	rel  string
+	opts []FollowOption
+}
+
+/*
+FollowError is returned when one of the hops of a FollowChain fails. */
+type FollowError struct {
+	// Hop is the position of the failing hop, starting at 1.
+	Hop int
+	Rel string
+	// URI is the resource the hop was followed from.
+	URI string
+	Err error
+}
+
+/*
+Error returns the error message. */
+func (e *FollowError) Error() string {
+	return fmt.Sprintf("follow hop %d (%q) from %s: %v", e.Hop, e.Rel, e.URI, e.Err)
+}
+
+/*
+Unwrap returns the underlying error. */
+func (e *FollowError) Unwrap() error {
+	return e.Err
+}
+
+/*
+NewFollowChain creates a new FollowChain starting at uri u, as resolved by the
+client c. */
+func NewFollowChain(c Getting, u string) *FollowChain {
+	f := FollowChain{
+		client: c,
+		start:  u,
+	}
+
+	return &f
+}
+
+/*
+Chain creates a new FollowChain starting at the resource. */
+func (r *Resource) Chain() *FollowChain {
+	return NewFollowChain(r.Client, r.Location().String())
+}
+
+/*
+Follow adds a hop following the relationship rt. Use the Expand option to
+provide variables for templated links. */
+func (c *FollowChain) Follow(rt string, opts ...FollowOption) *FollowChain {
+	n := FollowChain{
+		client: c.client,
+		start:  c.start,
+		hops:   append(append([]hop{}, c.hops...), hop{rel: rt, opts: opts}),
+	}
+
+	return &n
+}
+
+/*
+Resource follows every hop and returns the resource at the end of the chain. */
+func (c *FollowChain) Resource(ctx context.Context) (*Resource, error) {
+	r, err := c.client.Go(c.start)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, h := range c.hops {
+		repr, err := r.Representation(ctx)
+		if err == nil {
+			var t *Resource
+			t, err = r.followRel(repr, h.rel, h.opts)
+			if err == nil {
+				r = t
+				continue
+			}
+		}
+
+		return nil, &FollowError{
+			Hop: i + 1,
+			Rel: h.rel,
+			URI: r.Location().String(),
+			Err: err,
+		}
+	}
+
+	return r, nil
+}
+
+/*
+Get resolves the chain and fetches the representation of the resource at its
+end. */
+func (c *FollowChain) Get(ctx context.Context) (interface{}, error) {
+	r, err := c.Resource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetContext(ctx)
+}
+
+/*
+followRel follows the first link with reltype rt of representation repr. */
+func (r *Resource) followRel(repr Representor, rt string, opts []FollowOption) (*Resource, error) {
+	l, err := repr.GetLink(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.followLink(l, nil, opts)
+}
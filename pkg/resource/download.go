@@ -0,0 +1,224 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDownloadDelay caps the delay between the attempts of a Download.
+const maxDownloadDelay = 10 * time.Second
+
+/*
+DownloadOption configures a Download. */
+type DownloadOption func(*downloadOptions)
+
+/*
+downloadOptions holds the configuration of a Download. */
+type downloadOptions struct {
+	offset   int64
+	attempts int
+	ifRange  string
+	delay    time.Duration
+}
+
+/*
+ResumeAt continues an earlier transfer of which the first n bytes were
+already written. */
+func ResumeAt(n int64) DownloadOption {
+	return func(o *downloadOptions) {
+		o.offset = n
+	}
+}
+
+/*
+DownloadAttempts sets how many requests are made before a broken transfer
+is given up, 5 by default. */
+func DownloadAttempts(n int) DownloadOption {
+	return func(o *downloadOptions) {
+		if n > 0 {
+			o.attempts = n
+		}
+	}
+}
+
+/*
+DownloadBackoff sets the delay before the second attempt of a broken
+transfer, it doubles after every attempt up to 10 seconds. 200ms by default. */
+func DownloadBackoff(d time.Duration) DownloadOption {
+	return func(o *downloadOptions) {
+		if d > 0 {
+			o.delay = d
+		}
+	}
+}
+
+/*
+DownloadIfRange sets the validator, an entity tag or a date, the resource
+must still match for a transfer to be resumed. By default the validator of
+the first response is used, or with ResumeAt the one of the most recent
+exchange. */
+func DownloadIfRange(v string) DownloadOption {
+	return func(o *downloadOptions) {
+		o.ifRange = v
+	}
+}
+
+/*
+Download writes the body of the resource to w. When the transfer breaks it is
+continued with a range request from where it stopped, as long as the
+resource did not change; when it did, the download starts over. It returns
+the size of the body, or how far it got when it fails. Attempts are spaced
+out as set by DownloadBackoff.
+
+A resumed transfer that was already complete, to which the server responds
+with 416 Range Not Satisfiable and the size of the body, succeeds.
+
+Error responses are not retried here, the RetryPolicy of the client handles
+them. */
+func (r *Resource) Download(ctx context.Context, w io.WriterAt, opts ...DownloadOption) (int64, error) {
+	o := downloadOptions{
+		attempts: 5,
+		delay:    200 * time.Millisecond,
+	}
+
+	for _, v := range opts {
+		v(&o)
+	}
+
+	off := o.offset
+	v := o.ifRange
+
+	for attempt := 1; ; attempt++ {
+		so := []StreamOption{}
+		if off > 0 {
+			so = append(so, Range(off, -1))
+			if v != "" {
+				so = append(so, IfRange(v))
+			}
+		}
+
+		if attempt > 1 {
+			if err := backoff(ctx, o.delay, attempt); err != nil {
+				return off, err
+			}
+		}
+
+		s, err := r.Stream(ctx, so...)
+		if err != nil {
+			var re *ResponseError
+			if errors.As(err, &re) {
+				if off > 0 && re.StatusCode == http.StatusRequestedRangeNotSatisfiable && r.unsatisfiedSize() == off {
+					return off, nil
+				}
+
+				return off, err
+			}
+
+			if ctx.Err() != nil || attempt >= o.attempts {
+				return off, err
+			}
+
+			continue
+		}
+
+		if !s.Partial {
+			// The server sent the whole body, the resource changed or
+			// ranges are not supported.
+			off = 0
+		} else {
+			off = s.Offset
+		}
+
+		if !s.Partial || v == "" {
+			v = ifRangeValidator(s.Header.Get("ETag"), s.Header.Get("Last-Modified"))
+		}
+
+		n, err := io.Copy(&offsetWriter{w: w, off: off}, s)
+		s.Close()
+		off += n
+
+		if err == nil {
+			if s.Size < 0 || off >= s.Size {
+				return off, nil
+			}
+
+			err = io.ErrUnexpectedEOF
+		}
+
+		if ctx.Err() != nil {
+			return off, ctx.Err()
+		}
+
+		if attempt >= o.attempts {
+			return off, err
+		}
+	}
+}
+
+/*
+unsatisfiedSize returns the size of the body the 416 response of the most
+recent exchange gives in its Content-Range header, or -1. */
+func (r *Resource) unsatisfiedSize() int64 {
+	st := r.State()
+	if st == nil || st.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		return -1
+	}
+
+	v := st.Header.Get("Content-Range")
+	if !strings.HasPrefix(v, "bytes */") {
+		return -1
+	}
+
+	n, err := strconv.ParseInt(strings.TrimPrefix(v, "bytes */"), 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+/*
+backoff waits before attempt a (starting at 2) of a Download, delay d doubles
+after every attempt. It returns early with the error of the context when it
+is done. */
+func backoff(ctx context.Context, d time.Duration, a int) error {
+	for i := 2; i < a && d < maxDownloadDelay; i++ {
+		d *= 2
+	}
+
+	if d > maxDownloadDelay {
+		d = maxDownloadDelay
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+/*
+offsetWriter writes to an io.WriterAt as a sequential io.Writer, starting at
+off. */
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+/*
+Write implements io.Writer. */
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,21 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+)
+
+/*
+ResponseError is returned when the server responds with a status that is not
+a success. */
+type ResponseError struct {
+	Method     string
+	URI        string
+	StatusCode int
+}
+
+/*
+Error returns the error message. */
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URI, e.StatusCode, http.StatusText(e.StatusCode))
+}
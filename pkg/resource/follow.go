@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+/*
+FollowOption configures how a link is followed. */
+type FollowOption func(*followOptions)
+
+type followOptions struct {
+	transclude []string
+	variables  map[string]string
+}
+
+/*
+Expand provides the variables used to expand a templated link. */
+func Expand(v map[string]string) FollowOption {
+	return func(o *followOptions) {
+		o.variables = v
+	}
+}
+
+/*
+Transclude asks the server to embed the resources behind the given relations
+in the next response, with a `Prefer: transclude="..."` header. Use it with the
+relations that will be followed next, a server that supports it returns the
+whole subtree in one round trip. */
+func Transclude(rt ...string) FollowOption {
+	return func(o *followOptions) {
+		o.transclude = append(o.transclude, rt...)
+	}
+}
+
+/*
+apply sets the headers of the options on the next refresh of resource t. It
+does nothing when t already has a representation. */
+func (o followOptions) apply(t *Resource) {
+	if len(o.transclude) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.Representor != nil {
+		return
+	}
+
+	if t.nextRefreshHeaders == nil {
+		t.nextRefreshHeaders = map[string]string{}
+	}
+
+	t.nextRefreshHeaders["Prefer"] = "transclude=" + strconv.Quote(strings.Join(o.transclude, " "))
+}
+
+/*
+FollowAll follows every link with reltype rt, for example all the 'item' links
+of a collection. */
+func (r *Resource) FollowAll(rt string, opts ...FollowOption) ([]*Resource, error) {
+	return r.FollowAllContext(context.Background(), rt, opts...)
+}
+
+/*
+FollowAllContext is FollowAll, the context controls the lifetime of the
+request fetching the representation. */
+func (r *Resource) FollowAllContext(ctx context.Context, rt string, opts ...FollowOption) ([]*Resource, error) {
+	repr, err := r.Representation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ls := repr.GetLinks(rt)
+	rs := make([]*Resource, 0, len(ls))
+	for i := range ls {
+		t, err := r.followLink(&ls[i], nil, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		rs = append(rs, t)
+	}
+
+	return rs, nil
+}
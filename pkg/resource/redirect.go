@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"net/http"
+	"net/url"
+)
+
+/*
+Redirect is one hop of a redirect chain. */
+type Redirect struct {
+	// URI is the uri that was requested.
+	URI        *url.URL
+	StatusCode int
+	// Location is the uri the server redirected to.
+	Location *url.URL
+}
+
+/*
+Permanent returns true for the permanent redirects, 301 and 308. */
+func (r Redirect) Permanent() bool {
+	return r.StatusCode == http.StatusMovedPermanently || r.StatusCode == http.StatusPermanentRedirect
+}
+
+/*
+Redirects returns the redirects http.Client followed to get response resp,
+oldest first. */
+func Redirects(resp *http.Response) []Redirect {
+	c := []Redirect{}
+
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		from := req.Response.Request
+		if from == nil {
+			break
+		}
+
+		c = append([]Redirect{{
+			URI:        from.URL,
+			StatusCode: req.Response.StatusCode,
+			Location:   req.URL,
+		}}, c...)
+	}
+
+	return c
+}
+
+/*
+PermanentLocation returns where the permanent redirects at the start of chain
+c lead, and false when the chain does not start with one. */
+func PermanentLocation(c []Redirect) (*url.URL, bool) {
+	var u *url.URL
+	for _, r := range c {
+		if !r.Permanent() {
+			break
+		}
+
+		u = r.Location
+	}
+
+	return u, u != nil
+}
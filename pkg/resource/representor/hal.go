@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/identbase/getting/pkg/link"
 )
@@ -21,14 +22,21 @@ type HALRepresentor struct {
 	ContentType string
 	Body        interface{}
 	Links       link.LinkSet
+	Embedded    map[string][]Representor
 }
 
 type HALLink struct {
-	HRef  string `json:"href"`
-	Name  string `json:"name,omitempty"`
-	Title string `json:"title"`
-	// Templated string
-	Type string `json:"type,omitempty"`
+	HRef        string `json:"href"`
+	Name        string `json:"name,omitempty"`
+	Title       string `json:"title"`
+	Templated   bool   `json:"templated,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Hreflang    string `json:"hreflang,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+	Deprecation string `json:"deprecation,omitempty"`
+	// Extensions holds the link members HAL does not define as a JSON
+	// object, a string so HALLinks stay comparable.
+	Extensions string `json:"-"`
 }
 
 type HALBody struct {
@@ -37,49 +45,127 @@ type HALBody struct {
 	Links map[string][]HALLink `json:"_links,omitempty"`
 	// This should be only JSON acceptable types: string, int, float, bool
 	Properties map[string]interface{} `json:"-"`
-	Embedded   map[string]HALBody     `json:"_embedded,omitempty"`
+	Embedded   map[string][]HALBody   `json:"_embedded,omitempty"`
 }
 
+/*
+MarshalJSON converts a HALLink into JSON, with its extension members. */
+func (l HALLink) MarshalJSON() ([]byte, error) {
+	type halLink HALLink
+
+	buf, err := json.Marshal(halLink(l))
+	if err != nil || len(l.Extensions) == 0 {
+		return buf, err
+	}
+
+	r := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(l.Extensions), &r); err != nil {
+		return nil, err
+	}
+
+	// The members HAL defines win over extensions of the same name.
+	if err := json.Unmarshal(buf, &r); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(r)
+}
+
+/*
+mapInterfaceToHALLink converts a decoded link object into a HALLink. Members
+that are missing or not strings are left empty, members HAL does not define
+are kept in Extensions. */
 func mapInterfaceToHALLink(i map[string]interface{}) HALLink {
-	// TODO: This seems like there is a
-	// better way to do this
-	var link HALLink
-	_, nok := i["name"]
-	_, tok := i["type"]
-
-	if !nok && !tok {
-		link = HALLink{
-			HRef:  i["href"].(string),
-			Title: i["title"].(string),
+	str := func(k string) string {
+		v, _ := i[k].(string)
+		return v
+	}
+
+	t, _ := i["templated"].(bool)
+
+	var ext map[string]interface{}
+	for k, v := range i {
+		switch k {
+		case "href", "name", "title", "templated", "type", "hreflang", "profile", "deprecation":
+			continue
 		}
-	} else {
-		if nok && tok {
-			link = HALLink{
-				HRef:  i["href"].(string),
-				Name:  i["name"].(string),
-				Title: i["title"].(string),
-				Type:  i["type"].(string),
-			}
-		} else {
-			if nok {
-				link = HALLink{
-					HRef:  i["href"].(string),
-					Name:  i["name"].(string),
-					Title: i["title"].(string),
-				}
-			}
 
-			if tok {
-				link = HALLink{
-					HRef:  i["href"].(string),
-					Title: i["title"].(string),
-					Type:  i["type"].(string),
-				}
+		if ext == nil {
+			ext = map[string]interface{}{}
+		}
+		ext[k] = v
+	}
+
+	e := ""
+	if ext != nil {
+		// Maps are marshaled with sorted keys, equal members give equal
+		// strings.
+		buf, _ := json.Marshal(ext)
+		e = string(buf)
+	}
+
+	return HALLink{
+		HRef:        str("href"),
+		Name:        str("name"),
+		Title:       str("title"),
+		Templated:   t,
+		Type:        str("type"),
+		Hreflang:    str("hreflang"),
+		Profile:     str("profile"),
+		Deprecation: str("deprecation"),
+		Extensions:  e,
+	}
+}
+
+/*
+extensions converts the extension members of a HALLink into the attributes
+of a link.Link. Strings are kept, arrays give one value per element and other
+values are written as JSON. */
+func extensions(s string) map[string][]string {
+	e := map[string]interface{}{}
+	if s == "" || json.Unmarshal([]byte(s), &e) != nil || len(e) == 0 {
+		return nil
+	}
+
+	str := func(v interface{}) string {
+		if s, ok := v.(string); ok {
+			return s
+		}
+
+		buf, _ := json.Marshal(v)
+		return string(buf)
+	}
+
+	ext := map[string][]string{}
+	for k, v := range e {
+		k = strings.ToLower(k)
+		if a, ok := v.([]interface{}); ok {
+			for _, av := range a {
+				ext[k] = append(ext[k], str(av))
 			}
+		} else {
+			ext[k] = append(ext[k], str(v))
 		}
 	}
 
-	return link
+	return ext
+}
+
+/*
+mapInterfaceToHALBody converts a decoded embedded resource into a HALBody. */
+func mapInterfaceToHALBody(i map[string]interface{}) (HALBody, error) {
+	var h HALBody
+
+	buf, err := json.Marshal(i)
+	if err != nil {
+		return h, err
+	}
+
+	if err := json.Unmarshal(buf, &h); err != nil {
+		return h, err
+	}
+
+	return h, nil
 }
 
 /*
@@ -121,9 +207,42 @@ func (b *HALBody) UnmarshalJSON(d []byte) error {
 
 			b.Links = links
 		case "_embedded":
-			// TODO: Unmarshal HalBody recursively
-			// b.Embedded = v.([]*HALBody)
-			fmt.Println("TODO: Unmarshal _links", bk, bv)
+			embedded := map[string][]HALBody{}
+
+			ebuf, ok := bv.(map[string]interface{})
+			if !ok {
+				return errors.New("_embedded must be an object")
+			}
+
+			// _embedded: {
+			for ek, ev := range ebuf {
+				if evbuf, ok := ev.(map[string]interface{}); ok {
+					// author: {
+					h, err := mapInterfaceToHALBody(evbuf)
+					if err != nil {
+						return err
+					}
+
+					embedded[ek] = []HALBody{h}
+				} else if evbuf, ok := ev.([]interface{}); ok {
+					// item: [
+					for i := 0; i < len(evbuf); i++ {
+						evbufitem, ok := evbuf[i].(map[string]interface{})
+						if !ok {
+							return fmt.Errorf("_embedded %v must hold objects", ek)
+						}
+
+						h, err := mapInterfaceToHALBody(evbufitem)
+						if err != nil {
+							return err
+						}
+
+						embedded[ek] = append(embedded[ek], h)
+					}
+				}
+			}
+
+			b.Embedded = embedded
 		default:
 			if b.Properties == nil {
 				b.Properties = map[string]interface{}{}
@@ -146,28 +265,29 @@ func (b HALBody) MarshalJSON() ([]byte, error) {
 	}
 
 	if len(b.Embedded) > 0 {
-		r["_embedded"] = make(map[string]HALBody)
+		e := map[string]interface{}{}
 		for k, v := range b.Embedded {
-			// TODO: Marshal the _embedded stuff recursively
-			fmt.Println("TODO: Marshal _embedded", k, v)
+			if len(v) == 1 {
+				e[k] = v[0]
+			} else {
+				e[k] = v
+			}
 		}
+
+		r["_embedded"] = e
 	}
 
 	if len(b.Links) > 0 {
-		r["_links"] = map[string]interface{}{}
+		l := map[string]interface{}{}
 		for k, v := range b.Links {
 			if len(v) == 1 {
-				l := map[string]HALLink{}
 				l[k] = v[0]
-
-				r["_links"] = l
 			} else {
-				l := map[string][]HALLink{}
 				l[k] = v
-
-				r["_links"] = l
 			}
 		}
+
+		r["_links"] = l
 	}
 
 	buf, err := json.Marshal(&r)
@@ -210,24 +330,32 @@ func (r *HALRepresentor) parse(b []byte) (interface{}, error) {
 	return h, nil
 }
 
-// TODO: This function should be called in parse (and there should also be a
-// parseEmbedded called there too)
+// TODO: This function should be called in parse
 /*
 parseLinks converts HALLink into link.Link. */
 func (r *HALRepresentor) parseLinks(b interface{}) []*link.Link {
 	h := b.(HALBody)
 	l := []*link.Link{}
 
-	// TODO: This doesnt account for links in the Header
 	for k, v := range h.Links {
 		for i := 0; i < len(v); i++ {
+			var hreflang []string
+			if v[i].Hreflang != "" {
+				hreflang = []string{v[i].Hreflang}
+			}
+
 			l = append(l, &link.Link{
-				Context: r.URI.String(),
-				HRef:    v[i].HRef,
-				Rel:     k,
-				Name:    v[i].Name,
-				Title:   v[i].Title,
-				Type:    v[i].Type,
+				Context:     r.URI.String(),
+				HRef:        v[i].HRef,
+				Rel:         k,
+				Name:        v[i].Name,
+				Templated:   v[i].Templated,
+				Title:       v[i].Title,
+				Type:        v[i].Type,
+				Hreflang:    hreflang,
+				Profile:     v[i].Profile,
+				Deprecation: v[i].Deprecation,
+				Extensions:  extensions(v[i].Extensions),
 			})
 		}
 	}
@@ -235,6 +363,50 @@ func (r *HALRepresentor) parseLinks(b interface{}) []*link.Link {
 	return l
 }
 
+/*
+parseEmbedded converts the embedded HALBodies into HALRepresentors. Embedded
+resources without a self link cannot be addressed and are left out. */
+func (r *HALRepresentor) parseEmbedded(b interface{}) map[string][]Representor {
+	h := b.(HALBody)
+	e := map[string][]Representor{}
+
+	for k, v := range h.Embedded {
+		for i := 0; i < len(v); i++ {
+			self, ok := v[i].Links["self"]
+			if !ok || len(self) == 0 {
+				continue
+			}
+
+			u, err := r.URI.Parse(self[0].HRef)
+			if err != nil {
+				continue
+			}
+
+			er := HALRepresentor{
+				URI:         *u,
+				ContentType: r.ContentType,
+			}
+			er.setBody(v[i])
+
+			e[k] = append(e[k], &er)
+		}
+	}
+
+	return e
+}
+
+/*
+GetURI */
+func (r *HALRepresentor) GetURI() url.URL {
+	return r.URI
+}
+
+/*
+GetContentType */
+func (r *HALRepresentor) GetContentType() string {
+	return r.ContentType
+}
+
 /*
 GetBody */
 func (r *HALRepresentor) GetBody() interface{} {
@@ -256,6 +428,20 @@ func (r *HALRepresentor) setBody(b interface{}) {
 			r.Links.Set(v.Rel, append([]link.Link{}, *v))
 		}
 	}
+
+	r.Embedded = r.parseEmbedded(r.Body)
+}
+
+/*
+addLinks registers links found outside of the body, like the Link header. */
+func (r *HALRepresentor) addLinks(l []link.Link) {
+	if r.Links == nil {
+		r.Links = link.LinkSet{}
+	}
+
+	for _, v := range l {
+		r.Links.Add(v.Rel, v)
+	}
 }
 
 /*
@@ -281,6 +467,21 @@ func (r *HALRepresentor) GetLinks(rt string) []link.Link {
 	return r.Links.Get(rt)
 }
 
+/*
+GetEmbedded */
+func (r *HALRepresentor) GetEmbedded(rt string) []Representor {
+	if rt == "" {
+		e := []Representor{}
+		for _, v := range r.Embedded {
+			e = append(e, v...)
+		}
+
+		return e
+	}
+
+	return r.Embedded[rt]
+}
+
 /*
 HasLink */
 func (r *HALRepresentor) HasLink(rt string) bool {
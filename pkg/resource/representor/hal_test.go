@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -106,7 +107,7 @@ func Test_HALBody_UnmarshalJSON(t *testing.T) {
 			b := HALBody{
 				Links:      map[string][]HALLink{},
 				Properties: map[string]interface{}{},
-				Embedded:   map[string]HALBody{},
+				Embedded:   map[string][]HALBody{},
 			}
 			if err := b.UnmarshalJSON(tt.d); err != nil {
 				t.Errorf("HALBody.UnmarshalJSON() should not error, got %v", err)
@@ -175,3 +176,86 @@ func Test_HALBody_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func Test_HALRepresentor_GetEmbedded(t *testing.T) {
+	u, _ := url.Parse("http://localhost/articles")
+	b := bytes.NewBufferString(`{
+		"_links": {"self": {"href": "/articles", "title": "Articles"}},
+		"_embedded": {
+			"item": [
+				{"_links": {"self": {"href": "/articles/1", "title": "One"}}, "_embedded": {"author": {"_links": {"self": {"href": "/people/1", "title": "Author"}}}}},
+				{"_links": {"self": {"href": "/articles/2", "title": "Two"}}},
+				{"title": "no self link"}
+			]
+		}
+	}`).Bytes()
+
+	r, err := NewHALRepresentor(*u, "application/hal+json", b)
+	if err != nil {
+		t.Fatalf("NewHALRepresentor() should not error, got %v", err)
+	}
+
+	items := r.GetEmbedded("item")
+	if len(items) != 2 {
+		t.Fatalf("HALRepresentor.GetEmbedded() expected 2 items, got %d", len(items))
+	}
+
+	for i, want := range []string{"http://localhost/articles/1", "http://localhost/articles/2"} {
+		got := items[i].GetURI()
+		if got.String() != want {
+			t.Errorf("HALRepresentor.GetEmbedded() item %d expected uri '%v', got '%v'", i, want, got.String())
+		}
+	}
+
+	authors := items[0].GetEmbedded("author")
+	if len(authors) != 1 {
+		t.Fatalf("HALRepresentor.GetEmbedded() should parse recursively, got %d authors", len(authors))
+	}
+
+	l, err := authors[0].GetLink("self")
+	if err != nil || l.Title != "Author" {
+		t.Errorf("HALRepresentor.GetEmbedded() expected author self link, got %v %v", l, err)
+	}
+}
+
+func Test_HALRepresentor_GetLinkExtensions(t *testing.T) {
+	u, _ := url.Parse("http://localhost/articles/1")
+	b := bytes.NewBufferString(`{
+		"_links": {
+			"self": {"href": "/articles/1"},
+			"edit": {"href": "/articles/1/edit", "method": "PUT", "fields": ["title", "body"], "weight": 2}
+		}
+	}`).Bytes()
+
+	r, err := NewHALRepresentor(*u, "application/hal+json", b)
+	if err != nil {
+		t.Fatalf("NewHALRepresentor() should not error, got %v", err)
+	}
+
+	l, err := r.GetLink("edit")
+	if err != nil {
+		t.Fatalf("HALRepresentor.GetLink() should not error, got %v", err)
+	}
+
+	want := map[string][]string{
+		"method": []string{"PUT"},
+		"fields": []string{"title", "body"},
+		"weight": []string{"2"},
+	}
+	if fmt.Sprint(l.Extensions) != fmt.Sprint(want) {
+		t.Errorf("HALRepresentor.GetLink() expected extensions %v, got %v", want, l.Extensions)
+	}
+
+	if s, _ := r.GetLink("self"); s.Extensions != nil {
+		t.Errorf("HALRepresentor.GetLink() expected no extensions, got %v", s.Extensions)
+	}
+
+	out, err := json.Marshal(r.GetBody())
+	if err != nil {
+		t.Fatalf("json.Marshal() should not error, got %v", err)
+	}
+
+	if !strings.Contains(string(out), `"method":"PUT"`) {
+		t.Errorf("json.Marshal() should keep the link extensions, got %s", out)
+	}
+}
@@ -9,12 +9,24 @@ import (
 	"github.com/identbase/getting/pkg/link"
 )
 
+var (
+	// ErrUnsupportedContentType is returned when no Representor understands
+	// the content-type of a response.
+	ErrUnsupportedContentType = errors.New("unsupported content-type")
+	// ErrMissingContentType is returned when a response has no content-type.
+	ErrMissingContentType = errors.New("missing content-type")
+)
+
 /*
 Representor interface provides a way to handle many different types of
 acceptable representations.*/
 type Representor interface {
 	parse(b []byte) (interface{}, error)
+	addLinks(l []link.Link)
+	GetURI() url.URL
+	GetContentType() string
 	GetBody() interface{}
+	GetEmbedded(rt string) []Representor
 	GetLink(rt string) (*link.Link, error)
 	GetLinks(rt string) []link.Link
 	HasLink(rt string) bool
@@ -27,7 +39,7 @@ func Create(u url.URL, t string, b []byte) (Representor, error) {
 	case strings.Contains(t, "application/hal+json"):
 		return NewHALRepresentor(u, t, b)
 	default:
-		return nil, errors.New("unsupported content-type")
+		return nil, ErrUnsupportedContentType
 	}
 }
 
@@ -38,10 +50,24 @@ func CreateFromResponse(u url.URL, r http.Response, b []byte) (Representor, erro
 
 	ct := r.Header.Get("Content-Type")
 	if ct == "" {
-		return nil, errors.New("missing content-type")
+		return nil, ErrMissingContentType
+	}
+
+	repr, err := Create(u, ct, b)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: Check the header for Link
+	// Links in the Link header belong to the resource whatever its format.
+	for _, h := range r.Header["Link"] {
+		l, err := link.ParseHeader(u.String(), h)
+		if err != nil {
+			// A malformed header should not hide the body.
+			continue
+		}
+
+		repr.addLinks(l)
+	}
 
-	return Create(u, ct, b)
+	return repr, nil
 }
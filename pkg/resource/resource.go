@@ -1,10 +1,15 @@
 package resource
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
-	// "net/http/httputil"
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/identbase/getting/pkg/link"
 	"github.com/identbase/getting/pkg/resource/representor"
@@ -13,27 +18,54 @@ import (
 /*
 Resource represents endpoint on a server. The endpoint has a uri, you  might for
 example be able to GET its presentation. A resource may also have a list of
-links on them, pointing to other resources. */
+links on them, pointing to other resources.
+
+A Resource may be used from several goroutines, its representation can then
+change at any time, for example when a subscription updates it. Read it with
+Representation rather than through the Representor field. */
 type Resource struct {
-	Client             Getting
-	URI                *url.URL
-	ContentType        string
+	Client Getting
+	// URI is the uri the resource was created with, it never changes. The
+	// requests for the resource are sent to its Location.
+	URI         *url.URL
+	ContentType string
+	// Representor is the representation of the resource, nil until it is
+	// fetched. It is guarded by mu.
 	Representor        Representor
 	nextRefreshHeaders map[string]string
 	Variables          map[string]string
+	// state is the most recent exchange with the server.
+	state *State
+	// location is where the resource permanently moved to, nil when it did
+	// not move.
+	location *url.URL
+
+	// mu guards Representor, Variables, nextRefreshHeaders, state and
+	// location.
+	mu sync.Mutex
+
+	watchMu sync.Mutex
+	// watchers are called when the resource is invalidated, keyed by the
+	// id Watch gave them.
+	watchers map[int]func(*Resource)
+	watchID  int
 }
 
 /*
 Getting interface represents the getting client object. */
 type Getting interface {
 	Go(u string) (*Resource, error)
+	Do(req *http.Request) (*http.Response, error)
 }
 
 /*
 Representor interface represents the representation of the body of the request
 or response. */
 type Representor interface {
+	GetURI() url.URL
+	GetContentType() string
 	GetBody() interface{}
+	GetEmbedded(rt string) []representor.Representor
 	GetLink(rt string) (*link.Link, error)
 	GetLinks(rt string) []link.Link
 }
@@ -52,7 +84,14 @@ func New(c Getting, u *url.URL) *Resource {
 /*
 Link returns a specific link based on its rel. */
 func (r *Resource) Link(rt string) (*link.Link, error) {
-	repr, err := r.representation()
+	return r.LinkContext(context.Background(), rt)
+}
+
+/*
+LinkContext returns a specific link based on its rel, the context controls the
+lifetime of the request fetching the representation. */
+func (r *Resource) LinkContext(ctx context.Context, rt string) (*link.Link, error) {
+	repr, err := r.Representation(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -61,22 +100,26 @@ func (r *Resource) Link(rt string) (*link.Link, error) {
 
 /*
 refresh fetches the resource representation. */
-func (r *Resource) refresh() (interface{}, error) {
-	c := &http.Client{}
-
+func (r *Resource) refresh(ctx context.Context) (Representor, error) {
 	// TODO: Figure out if we should be setting the body (3rd) parameter
-	req, err := http.NewRequest("GET", r.URI.String(), nil)
+	u := r.Location().String()
+	req, err := http.NewRequest("GET", u, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", r.ContentType)
+	if r.ContentType != "" {
+		req.Header.Set("Accept", r.ContentType)
+	}
 
+	r.mu.Lock()
 	for k, v := range r.nextRefreshHeaders {
 		req.Header.Set(k, v)
 	}
+	r.mu.Unlock()
 
-	resp, err := c.Do(req)
+	t := time.Now()
+	resp, err := r.Client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -88,74 +131,134 @@ func (r *Resource) refresh() (interface{}, error) {
 		return nil, err
 	}
 
-	r.Representor, err = representor.CreateFromResponse(*r.URI, *resp, body)
+	st := newState(req, resp, t, int64(len(body)))
+	r.setState(st)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &ResponseError{
+			Method:     "GET",
+			URI:        u,
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	// Relative links are relative to where the representation came from,
+	// which is not the uri of the resource when the request was redirected.
+	repr, err := representor.CreateFromResponse(*st.URI, *resp, body)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.Representor.GetBody(), nil
+	r.mu.Lock()
+	r.Representor = repr
+	r.nextRefreshHeaders = nil
+	r.mu.Unlock()
+
+	r.storeEmbedded(repr)
+
+	return repr, nil
 }
 
 /*
-representation returns the resource in the specified representation. */
-func (r *Resource) representation() (Representor, error) {
-	if r.Representor == nil {
-		// TODO: Use Resource.refresh() once we figure out how to not
-		// cause a race condition
-		// r.refresh()
-		c := &http.Client{}
+storeEmbedded hands the embedded resources of a representation to the client,
+so following a link to one of them does not need another request. */
+func (r *Resource) storeEmbedded(repr Representor) {
+	for _, e := range repr.GetEmbedded("") {
+		u := e.GetURI()
 
-		// TODO: Figure out if we should be setting the body (3rd) parameter
-		req, err := http.NewRequest("GET", r.URI.String(), nil)
+		t, err := r.Client.Go(u.String())
 		if err != nil {
-			return nil, err
+			continue
 		}
 
-		// req.Header.Set("Accept", r.ContentType)
+		t.setRepresentation(e)
+		t.storeEmbedded(e)
+	}
+}
 
-		for k, v := range r.nextRefreshHeaders {
-			req.Header.Set(k, v)
-		}
+/*
+Representation returns the representation of the resource, fetching it when
+the resource does not have one yet. */
+func (r *Resource) Representation(ctx context.Context) (Representor, error) {
+	if repr := r.current(); repr != nil {
+		return repr, nil
+	}
 
-		// dump, err := httputil.DumpRequestOut(req, true)
-		// if err != nil {
-		// 	return nil, err
-		// }
+	return r.refresh(ctx)
+}
 
-		resp, err := c.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
+/*
+Location returns the uri the requests for the resource are sent to: its URI,
+or the uri it moved to with MoveTo. */
+func (r *Resource) Location() *url.URL {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := *r.URI
+	if r.location != nil {
+		u = *r.location
+	}
 
-		// fmt.Printf("REQUEST %v %q", req, dump)
+	return &u
+}
 
-		// dump, err = httputil.DumpResponse(resp, true)
-		// if err != nil {
-		// 	return nil, err
-		// }
+/*
+MoveTo sends the requests for the resource to uri u from now on, for clients
+that follow permanent redirects. URI is left as it is. */
+func (r *Resource) MoveTo(u *url.URL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l := *u
+	r.location = &l
+}
 
-		// fmt.Printf("RESPONSE %v %q", resp, dump)
+/*
+current returns the representation of the resource, nil when it has none. */
+func (r *Resource) current() Representor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
+	return r.Representor
+}
 
-		r.Representor, err = representor.CreateFromResponse(*r.URI, *resp, body)
-		if err != nil {
-			return nil, err
-		}
+/*
+setRepresentation replaces the representation of the resource. */
+func (r *Resource) setRepresentation(repr Representor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	}
+	r.Representor = repr
+}
+
+/*
+setState records the most recent exchange with the server. */
+func (r *Resource) setState(s *State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state = s
+}
 
-	return r.Representor, nil
+/*
+Invalidate discards the representation, the next call to Get fetches it again
+from the server. */
+func (r *Resource) Invalidate() {
+	r.setRepresentation(nil)
+	r.notify()
 }
 
 /*
 Get fetches the resource representation. */
 func (r *Resource) Get() (interface{}, error) {
-	repr, err := r.representation()
+	return r.GetContext(context.Background())
+}
+
+/*
+GetContext fetches the resource representation, the context controls the
+lifetime of the request. */
+func (r *Resource) GetContext(ctx context.Context) (interface{}, error) {
+	repr, err := r.Representation(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +269,7 @@ func (r *Resource) Get() (interface{}, error) {
 /*
 Go resolves a new resource based on a relative URI. */
 func (r *Resource) Go(u string) (*Resource, error) {
-	h, err := r.URI.Parse(u)
+	h, err := r.Location().Parse(u)
 	if err != nil {
 		return nil, err
 	}
@@ -177,26 +280,246 @@ func (r *Resource) Go(u string) (*Resource, error) {
 /*
 Follow follows a relationship, based on its reltype. For example, this might be
 'alternate', 'item', 'edit', or a custom url-based one. */
-func (r *Resource) Follow(rt string, v map[string]string) (*Resource, error) {
-	l, err := r.Link(rt)
+func (r *Resource) Follow(rt string, v map[string]string, opts ...FollowOption) (*Resource, error) {
+	return r.FollowContext(context.Background(), rt, v, opts...)
+}
+
+/*
+FollowContext follows a relationship, the context controls the lifetime of the
+request fetching the representation. */
+func (r *Resource) FollowContext(ctx context.Context, rt string, v map[string]string, opts ...FollowOption) (*Resource, error) {
+	l, err := r.LinkContext(ctx, rt)
 	if err != nil {
 		return nil, err
 	}
 
+	r.mu.Lock()
 	r.Variables = v
-	if l.Templated && r.Variables != nil && len(r.Variables) > 0 {
-		h, err := l.Expand(r.Variables)
-		if err != nil {
-			return nil, err
-		}
+	r.mu.Unlock()
+
+	return r.followLink(l, v, opts)
+}
+
+/*
+FindLink returns the first link with reltype rt matching every matcher, for
+example r.FindLink("alternate", link.WithType("text/csv")). */
+func (r *Resource) FindLink(rt string, m ...link.Matcher) (*link.Link, error) {
+	return r.FindLinkContext(context.Background(), rt, m...)
+}
+
+/*
+FindLinkContext is FindLink, the context controls the lifetime of the request
+fetching the representation. */
+func (r *Resource) FindLinkContext(ctx context.Context, rt string, m ...link.Matcher) (*link.Link, error) {
+	repr, err := r.Representation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l := link.Filter(repr.GetLinks(rt), m...)
+	if len(l) == 0 {
+		return nil, errors.New("link not found")
+	}
+
+	return &l[0], nil
+}
+
+/*
+FollowWhere follows the first link with reltype rt matching every matcher. */
+func (r *Resource) FollowWhere(rt string, v map[string]string, m ...link.Matcher) (*Resource, error) {
+	return r.FollowWhereContext(context.Background(), rt, v, m...)
+}
+
+/*
+FollowWhereContext is FollowWhere, the context controls the lifetime of the
+request fetching the representation. */
+func (r *Resource) FollowWhereContext(ctx context.Context, rt string, v map[string]string, m ...link.Matcher) (*Resource, error) {
+	l, err := r.FindLinkContext(ctx, rt, m...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.followLink(l, v, nil)
+}
+
+/*
+followLink resolves a link, expanding it with variables v (or those of the
+Expand option) when it is templated, and returns the resource it points to. */
+func (r *Resource) followLink(l *link.Link, v map[string]string, opts []FollowOption) (*Resource, error) {
+	o := followOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if v == nil {
+		v = o.variables
+	}
+
+	var h string
+	var err error
 
-		return r.Go(h)
+	if l.Templated {
+		h, err = l.Expand(v)
 	} else {
-		h, err := l.Resolve()
-		if err != nil {
+		h, err = l.Resolve()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := r.Go(h)
+	if err != nil {
+		return nil, err
+	}
+
+	o.apply(t)
+
+	return t, nil
+}
+
+/*
+Put replaces the resource with body b, of content-type ct. */
+func (r *Resource) Put(ctx context.Context, ct string, b []byte) error {
+	resp, body, err := r.send(ctx, "PUT", ct, b)
+	if err != nil {
+		return err
+	}
+
+	return r.prepopulate(resp, body, r.Location().String())
+}
+
+/*
+Patch partially updates the resource with body b, of content-type ct. */
+func (r *Resource) Patch(ctx context.Context, ct string, b []byte) error {
+	resp, body, err := r.send(ctx, "PATCH", ct, b)
+	if err != nil {
+		return err
+	}
+
+	return r.prepopulate(resp, body, r.Location().String())
+}
+
+/*
+Delete deletes the resource. */
+func (r *Resource) Delete(ctx context.Context) error {
+	_, _, err := r.send(ctx, "DELETE", "", nil)
+
+	return err
+}
+
+/*
+Post sends body b, of content-type ct, to the resource. When the server
+responds with a Location header the resource it points to is returned,
+otherwise the returned resource is nil.
+
+When the server responds with `201 Created` and a body whose Content-Location
+equals the Location, the body is the representation of the new resource and
+following it does not need another request. */
+func (r *Resource) Post(ctx context.Context, ct string, b []byte) (*Resource, error) {
+	resp, body, err := r.send(ctx, "POST", ct, b)
+	if err != nil {
+		return nil, err
+	}
+
+	l := resp.Header.Get("Location")
+	if l == "" {
+		return nil, nil
+	}
+
+	n, err := r.Go(l)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusCreated {
+		if err := r.prepopulate(resp, body, n.Location().String()); err != nil {
 			return nil, err
 		}
+	}
+
+	return n, nil
+}
+
+/*
+send performs a request with a body on the resource, failing when the server
+does not respond with a 2xx status. The response body is read and returned. */
+func (r *Resource) send(ctx context.Context, m string, ct string, b []byte) (*http.Response, []byte, error) {
+	var body io.Reader
+	if b != nil {
+		body = bytes.NewReader(b)
+	}
+
+	return r.sendReader(ctx, m, ct, body)
+}
+
+/*
+sendReader is send with a request body read from b, which may be nil. */
+func (r *Resource) sendReader(ctx context.Context, m string, ct string, b io.Reader) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(m, r.Location().String(), b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ct != "" {
+		req.Header.Set("Content-Type", ct)
+	}
+
+	t := time.Now()
+	resp, err := r.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	rb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.setState(newState(req, resp, t, int64(len(rb))))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, &ResponseError{
+			Method:     m,
+			URI:        r.Location().String(),
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	return resp, rb, nil
+}
+
+/*
+prepopulate stores the representation returned by a write request on the
+resource at uri u, when the Content-Location header says the body represents
+that resource. Bodies in a format no Representor understands are ignored. */
+func (r *Resource) prepopulate(resp *http.Response, b []byte, u string) error {
+	cl := resp.Header.Get("Content-Location")
+	if cl == "" || len(b) == 0 {
+		return nil
+	}
+
+	c, err := r.Location().Parse(cl)
+	if err != nil {
+		return nil
+	}
+
+	if c.String() != u {
+		return nil
+	}
+
+	repr, err := representor.CreateFromResponse(*c, *resp, b)
+	if err != nil {
+		return nil
+	}
 
-		return r.Go(h)
+	t, err := r.Go(u)
+	if err != nil {
+		return err
 	}
+
+	t.setRepresentation(repr)
+
+	return nil
 }
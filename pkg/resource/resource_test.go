@@ -1,5 +1,159 @@
 package resource
 
 import (
-// "testing"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/identbase/getting/pkg/link"
+	"github.com/identbase/getting/pkg/resource/representor"
 )
+
+/*
+client is a Getting that never talks to a server. */
+type client struct{}
+
+func (c client) Go(u string) (*Resource, error) {
+	p, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(c, p), nil
+}
+
+func (c client) Do(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("no server")
+}
+
+const alternates = `{
+	"_links": {
+		"self": {"href": "/report"},
+		"alternate": [
+			{"href": "/report.csv", "type": "text/csv", "hreflang": "en"},
+			{"href": "/report.fr.csv", "type": "text/csv", "hreflang": "fr"},
+			{"href": "/report.pdf", "type": "application/pdf", "hreflang": "en", "name": "print"}
+		]
+	}
+}`
+
+func newResource(t *testing.T) *Resource {
+	u, _ := url.Parse("http://localhost:8000/report")
+
+	repr, err := representor.Create(*u, "application/hal+json", []byte(alternates))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(client{}, u)
+	r.Representor = repr
+
+	return r
+}
+
+func Test_Resource_FindLink(t *testing.T) {
+	tests := []struct {
+		name string
+		rt   string
+		m    []link.Matcher
+		want string
+		err  bool
+	}{
+		{
+			"success match",
+			"alternate",
+			[]link.Matcher{link.WithType("application/pdf")},
+			"/report.pdf",
+			false,
+		},
+		{
+			"success several matches returns the first",
+			"alternate",
+			[]link.Matcher{link.WithType("text/csv")},
+			"/report.csv",
+			false,
+		},
+		{
+			"success every matcher",
+			"alternate",
+			[]link.Matcher{link.WithType("text/csv"), link.WithHreflang("fr")},
+			"/report.fr.csv",
+			false,
+		},
+		{
+			"error no match",
+			"alternate",
+			[]link.Matcher{link.WithName("print"), link.WithType("text/csv")},
+			"",
+			true,
+		},
+		{
+			"error no rel",
+			"edit",
+			nil,
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := newResource(t).FindLink(tt.rt, tt.m...)
+
+			if tt.err && err == nil {
+				t.Errorf("Resource.FindLink() should error, got %v", l)
+			} else if !tt.err && err != nil {
+				t.Errorf("Resource.FindLink() errored with %v when it shouldnt have", err)
+			}
+
+			if !tt.err && err == nil && l.HRef != tt.want {
+				t.Errorf("Resource.FindLink() = %v, want %v", l.HRef, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Resource_FollowWhere(t *testing.T) {
+	tests := []struct {
+		name string
+		m    []link.Matcher
+		want string
+		err  bool
+	}{
+		{
+			"success match",
+			[]link.Matcher{link.WithHreflang("fr")},
+			"http://localhost:8000/report.fr.csv",
+			false,
+		},
+		{
+			"success several matches follows the first",
+			[]link.Matcher{link.WithHreflang("en")},
+			"http://localhost:8000/report.csv",
+			false,
+		},
+		{
+			"error no match",
+			[]link.Matcher{link.WithHreflang("de")},
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newResource(t).FollowWhere("alternate", nil, tt.m...)
+
+			if tt.err && err == nil {
+				t.Errorf("Resource.FollowWhere() should error, got %v", r)
+			} else if !tt.err && err != nil {
+				t.Errorf("Resource.FollowWhere() errored with %v when it shouldnt have", err)
+			}
+
+			if !tt.err && err == nil && r.URI.String() != tt.want {
+				t.Errorf("Resource.FollowWhere() = %v, want %v", r.URI, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package resource
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+/*
+State is a snapshot of the most recent HTTP exchange of a resource. */
+type State struct {
+	Method     string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// URI is the effective uri of the response, which differs from the
+	// uri of the resource when the request was redirected.
+	URI *url.URL
+	// Redirects is the redirect chain that led to URI, oldest first.
+	Redirects    []Redirect
+	ETag         string
+	LastModified string
+	// Fetched is when the response was received.
+	Fetched time.Time
+	// Duration is how long the exchange took, from sending the request to
+	// reading the whole response body.
+	Duration time.Duration
+	// Size is the number of bytes in the response body.
+	Size int64
+}
+
+/*
+newState creates the State of response resp to request req, that was sent at
+t and whose body was s bytes long. */
+func newState(req *http.Request, resp *http.Response, t time.Time, s int64) *State {
+	u := *req.URL
+	if resp.Request != nil {
+		u = *resp.Request.URL
+	}
+
+	st := State{
+		Method:       req.Method,
+		StatusCode:   resp.StatusCode,
+		Status:       resp.Status,
+		Header:       resp.Header.Clone(),
+		URI:          &u,
+		Redirects:    Redirects(resp),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched:      time.Now(),
+		Size:         s,
+	}
+	st.Duration = st.Fetched.Sub(t)
+
+	return &st
+}
+
+/*
+State returns a snapshot of the most recent exchange with the server, nil
+when the resource was never fetched or written, for example when its
+representation was embedded in another one. Changing the snapshot does not
+change the resource. */
+func (r *Resource) State() *State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state == nil {
+		return nil
+	}
+
+	s := *r.state
+	s.Header = r.state.Header.Clone()
+
+	u := *r.state.URI
+	s.URI = &u
+	s.Redirects = append([]Redirect{}, r.state.Redirects...)
+
+	return &s
+}
+
+/*
+effectiveURI returns the uri the representation of the resource came from,
+which is the location of the resource unless the last request was redirected. */
+func (r *Resource) effectiveURI() url.URL {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state != nil && r.state.URI != nil {
+		return *r.state.URI
+	}
+
+	if r.location != nil {
+		return *r.location
+	}
+
+	return *r.URI
+}
@@ -0,0 +1,269 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Stream is the body of a resource being read from the server. It must be
+closed once read. */
+type Stream struct {
+	io.ReadCloser
+	StatusCode int
+	Header     http.Header
+	// ContentType is the media type of the body.
+	ContentType string
+	// ContentLength is the size of the body in bytes, -1 when unknown.
+	ContentLength int64
+	// Partial is true when the body is a byte range of the resource, the
+	// server responded with 206 Partial Content.
+	Partial bool
+	// Offset is the position of the first byte of the body in the
+	// resource, 0 unless Partial.
+	Offset int64
+	// Size is the size of the whole resource in bytes, -1 when unknown.
+	Size int64
+}
+
+/*
+StreamOption configures a Stream request. */
+type StreamOption func(*streamOptions)
+
+/*
+streamOptions holds the configuration of a Stream request. */
+type streamOptions struct {
+	start   int64
+	end     int64
+	ranged  bool
+	ifRange string
+}
+
+/*
+Range only requests the bytes from start to end, both included, of the
+resource. An end below zero means up to the last byte. */
+func Range(start int64, end int64) StreamOption {
+	return func(o *streamOptions) {
+		o.start = start
+		o.end = end
+		o.ranged = true
+	}
+}
+
+/*
+IfRange only lets the server send the range when the resource still matches
+validator v, an entity tag or a date. Otherwise the whole resource is sent.
+By default the ETag of the most recent exchange is used, or its
+Last-Modified date when there is no strong ETag. */
+func IfRange(v string) StreamOption {
+	return func(o *streamOptions) {
+		o.ifRange = v
+	}
+}
+
+/*
+UploadOption configures an upload. */
+type UploadOption func(*uploadOptions)
+
+/*
+uploadOptions holds the configuration of an upload. */
+type uploadOptions struct {
+	method string
+}
+
+/*
+UploadMethod sets the method of an upload, PUT by default. */
+func UploadMethod(m string) UploadOption {
+	return func(o *uploadOptions) {
+		o.method = m
+	}
+}
+
+/*
+Stream fetches the body of the resource without reading it into memory, and
+whatever its content-type, for downloads such as rel="enclosure" links or
+CSV exports. The response is not cached and the representation of the
+resource does not change.
+
+With the Range option only part of the body is requested. The server may
+still send all of it, Partial tells which one it did.
+
+The State of a stream is recorded when the headers arrive, its Size is the
+Content-Length. */
+func (r *Resource) Stream(ctx context.Context, opts ...StreamOption) (*Stream, error) {
+	o := streamOptions{}
+	for _, v := range opts {
+		v(&o)
+	}
+
+	req, err := http.NewRequest("GET", r.Location().String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.ranged {
+		req.Header.Set("Range", byteRange(o.start, o.end))
+
+		if o.ifRange == "" {
+			o.ifRange = r.validator()
+		}
+		if o.ifRange != "" {
+			req.Header.Set("If-Range", o.ifRange)
+		}
+	}
+
+	// Ask the client not to store the response, a cache would have to read
+	// the whole body.
+	req.Header.Set("Cache-Control", "no-store")
+
+	t := time.Now()
+	resp, err := r.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	r.setState(newState(req, resp, t, resp.ContentLength))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+
+		return nil, &ResponseError{
+			Method:     "GET",
+			URI:        r.Location().String(),
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	s := Stream{
+		ReadCloser:    resp.Body,
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		Size:          resp.ContentLength,
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		start, size, err := contentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		s.Partial = true
+		s.Offset = start
+		s.Size = size
+	}
+
+	return &s, nil
+}
+
+/*
+validator returns the validator of the most recent exchange that can be used
+in an If-Range header: a strong ETag, or else the Last-Modified date. */
+func (r *Resource) validator() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state == nil {
+		return ""
+	}
+
+	return ifRangeValidator(r.state.ETag, r.state.LastModified)
+}
+
+/*
+ifRangeValidator returns entity tag e when it is strong, If-Range does not
+allow weak ones, and Last-Modified date lm otherwise. */
+func ifRangeValidator(e string, lm string) string {
+	if e != "" && !strings.HasPrefix(e, "W/") {
+		return e
+	}
+
+	return lm
+}
+
+/*
+byteRange returns the value of a Range header for the bytes from start to
+end, up to the last byte when end is below zero. */
+func byteRange(start int64, end int64) string {
+	if end < 0 {
+		return fmt.Sprintf("bytes=%d-", start)
+	}
+
+	return fmt.Sprintf("bytes=%d-%d", start, end)
+}
+
+/*
+contentRange parses a Content-Range header, like "bytes 100-199/1000", and
+returns the position of the first byte and the size of the resource, -1 when
+the size is unknown. */
+func contentRange(v string) (int64, int64, error) {
+	if !strings.HasPrefix(v, "bytes ") {
+		return 0, 0, fmt.Errorf("invalid content-range %q", v)
+	}
+
+	p := strings.SplitN(strings.TrimPrefix(v, "bytes "), "/", 2)
+	if len(p) != 2 {
+		return 0, 0, fmt.Errorf("invalid content-range %q", v)
+	}
+
+	r := strings.SplitN(p[0], "-", 2)
+	start, err := strconv.ParseInt(r[0], 10, 64)
+	if err != nil || len(r) != 2 {
+		return 0, 0, fmt.Errorf("invalid content-range %q", v)
+	}
+
+	if p[1] == "*" {
+		return start, -1, nil
+	}
+
+	size, err := strconv.ParseInt(p[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid content-range %q", v)
+	}
+
+	return start, size, nil
+}
+
+/*
+Upload sends the content of b, of content-type ct, to the resource with a PUT
+request, or the method set with UploadMethod, without reading it into memory.
+Like Post it returns the resource of the Location header, or nil. */
+func (r *Resource) Upload(ctx context.Context, b io.Reader, ct string, opts ...UploadOption) (*Resource, error) {
+	o := uploadOptions{
+		method: "PUT",
+	}
+
+	for _, v := range opts {
+		v(&o)
+	}
+
+	resp, body, err := r.sendReader(ctx, o.method, ct, b)
+	if err != nil {
+		return nil, err
+	}
+
+	l := resp.Header.Get("Location")
+	if l == "" {
+		return nil, r.prepopulate(resp, body, r.Location().String())
+	}
+
+	n, err := r.Go(l)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusCreated {
+		if err := r.prepopulate(resp, body, n.Location().String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,274 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/identbase/getting/pkg/link"
+	"github.com/identbase/getting/pkg/resource/representor"
+	"github.com/identbase/getting/pkg/sse"
+)
+
+/*
+ErrNoMonitor is returned by Subscribe when the resource has no link to an
+event stream. */
+var ErrNoMonitor = errors.New("no monitor link")
+
+/*
+ErrStreamClosed is the Err of the last Update of a subscription the server
+ended, by answering a reconnection with 204 No Content. */
+var ErrStreamClosed = errors.New("event stream closed by the server")
+
+/*
+Evicter is implemented by clients that cache responses, like Getting. */
+type Evicter interface {
+	Evict(u string)
+}
+
+/*
+Update is a change to a subscribed resource. */
+type Update struct {
+	// Event is the server-sent event that announced the change.
+	Event sse.Event
+	// Pushed is true when the event carried the new representation, false
+	// when it was fetched again.
+	Pushed bool
+	// Err is why the resource could not be updated. When the subscription
+	// ends because of an error, it is the Err of the last Update.
+	Err error
+}
+
+/*
+SubscribeOption configures a subscription. */
+type SubscribeOption func(*subscribeOptions)
+
+/*
+subscribeOptions holds the configuration of a subscription. */
+type subscribeOptions struct {
+	pushed string
+	delay  time.Duration
+}
+
+/*
+ApplyPushed applies the data of every event as the new representation of the
+resource, of content-type ct, instead of fetching it again. Events whose
+data cannot be parsed still trigger a fetch. */
+func ApplyPushed(ct string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.pushed = ct
+	}
+}
+
+/*
+ReconnectDelay sets how long to wait before reconnecting a broken event
+stream, 3 seconds by default. The server can change it with the retry field. */
+func ReconnectDelay(d time.Duration) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.delay = d
+	}
+}
+
+/*
+Subscribe opens the Server-Sent Events stream the resource links to, with
+rel="monitor" or a link of type text/event-stream, and updates the resource
+for every event it receives. The updates are sent on the returned channel,
+which is closed when the context is done or the subscription fails. Broken
+streams are reopened with the Last-Event-ID of the last event.
+
+The resource is updated from the goroutine of the subscription, before the
+Update is sent, and can be used from other goroutines meanwhile. The channel
+must be read for the subscription to go on. */
+func (r *Resource) Subscribe(ctx context.Context, opts ...SubscribeOption) (<-chan Update, error) {
+	o := subscribeOptions{
+		delay: 3 * time.Second,
+	}
+
+	for _, v := range opts {
+		v(&o)
+	}
+
+	l, err := r.monitor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := l.Expand(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := r.events(ctx, u, "")
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(chan Update)
+	go r.subscription(ctx, u, b, o, c)
+
+	return c, nil
+}
+
+/*
+monitor returns the link to the event stream of the resource. */
+func (r *Resource) monitor(ctx context.Context) (*link.Link, error) {
+	repr, err := r.Representation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if l := repr.GetLinks("monitor"); len(l) > 0 {
+		return &l[0], nil
+	}
+
+	for _, l := range repr.GetLinks("") {
+		if t, _, err := mime.ParseMediaType(l.Type); err == nil && t == "text/event-stream" {
+			return &l, nil
+		}
+	}
+
+	return nil, ErrNoMonitor
+}
+
+/*
+events opens the event stream at uri u, resuming after event id when it is
+set. */
+func (r *Resource) events(ctx context.Context, u string, id string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-store")
+	if id != "" {
+		req.Header.Set("Last-Event-ID", id)
+	}
+
+	resp, err := r.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNoContent {
+		resp.Body.Close()
+		return nil, ErrStreamClosed
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+
+		return nil, &ResponseError{
+			Method:     "GET",
+			URI:        u,
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	if t, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || t != "text/event-stream" {
+		resp.Body.Close()
+		return nil, representor.ErrUnsupportedContentType
+	}
+
+	return resp.Body, nil
+}
+
+/*
+subscription reads the event stream b at uri u, and reopens it when it
+breaks, until the context is done. */
+func (r *Resource) subscription(ctx context.Context, u string, b io.ReadCloser, o subscribeOptions, c chan<- Update) {
+	defer close(c)
+
+	id := ""
+	for {
+		d := sse.NewDecoder(b)
+		d.SetLastEventID(id)
+
+		for {
+			e, err := d.Next()
+			if err != nil {
+				break
+			}
+
+			select {
+			case c <- r.update(ctx, *e, o):
+			case <-ctx.Done():
+				b.Close()
+				return
+			}
+		}
+
+		b.Close()
+
+		id = d.LastEventID()
+		if d.Retry() > 0 {
+			o.delay = d.Retry()
+		}
+
+		for {
+			select {
+			case <-time.After(o.delay):
+			case <-ctx.Done():
+				return
+			}
+
+			var err error
+			b, err = r.events(ctx, u, id)
+			if err == nil {
+				break
+			}
+
+			// Network errors are worth another try, the server saying
+			// no is not.
+			var re *ResponseError
+			if errors.As(err, &re) || err == ErrStreamClosed || err == representor.ErrUnsupportedContentType {
+				select {
+				case c <- Update{Err: err}:
+				case <-ctx.Done():
+				}
+
+				return
+			}
+		}
+	}
+}
+
+/*
+update applies event e to the resource. */
+func (r *Resource) update(ctx context.Context, e sse.Event, o subscribeOptions) Update {
+	up := Update{
+		Event: e,
+	}
+
+	if o.pushed != "" && e.Data != "" {
+		repr, err := representor.Create(r.effectiveURI(), o.pushed, []byte(e.Data))
+		if err == nil {
+			// The representation is replaced at once, the watchers are only
+			// told once it is, so they never fetch the old one again.
+			r.evict()
+			r.setRepresentation(repr)
+			r.storeEmbedded(repr)
+			r.notify()
+
+			up.Pushed = true
+			return up
+		}
+	}
+
+	r.evict()
+	r.Invalidate()
+	_, up.Err = r.GetContext(ctx)
+
+	return up
+}
+
+/*
+evict deletes whatever the client cached for the resource. */
+func (r *Resource) evict() {
+	if e, ok := r.Client.(Evicter); ok {
+		e.Evict(r.Location().String())
+	}
+}
@@ -0,0 +1,51 @@
+package resource
+
+/*
+Watch calls f every time the resource is invalidated, whether by a write
+request, a Link rel="invalidates", a subscription or a change notification
+pushed by the server. When a subscription pushes a new representation, f is
+called once it is set. f is called from the goroutine that invalidated the
+resource. The returned function stops the watch. */
+func (r *Resource) Watch(f func(*Resource)) func() {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+
+	if r.watchers == nil {
+		r.watchers = map[int]func(*Resource){}
+	}
+
+	r.watchID++
+	id := r.watchID
+	r.watchers[id] = f
+
+	return func() {
+		r.watchMu.Lock()
+		defer r.watchMu.Unlock()
+
+		delete(r.watchers, id)
+	}
+}
+
+/*
+notify calls the watchers of the resource. */
+func (r *Resource) notify() {
+	r.watchMu.Lock()
+	w := make([]func(*Resource), 0, len(r.watchers))
+	for _, f := range r.watchers {
+		w = append(w, f)
+	}
+	r.watchMu.Unlock()
+
+	for _, f := range w {
+		f(r)
+	}
+}
+
+/*
+Watched reports whether the resource has watchers. */
+func (r *Resource) Watched() bool {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+
+	return len(r.watchers) > 0
+}
@@ -0,0 +1,163 @@
+package sse
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Event is a server-sent event. */
+type Event struct {
+	// ID is the last event id the stream set, it is kept from one event to
+	// the next until the server changes it.
+	ID string
+	// Type is the event type, "message" when the server did not name it.
+	Type string
+	Data string
+}
+
+/*
+Decoder reads server-sent events from a text/event-stream body, as specified
+by the HTML Living Standard. */
+type Decoder struct {
+	s     *bufio.Scanner
+	id    string
+	retry time.Duration
+}
+
+/*
+NewDecoder creates a new Decoder reading from r. */
+func NewDecoder(r io.Reader) *Decoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), 1<<20)
+	s.Split(scanLines)
+
+	d := Decoder{
+		s: s,
+	}
+
+	return &d
+}
+
+/*
+SetLastEventID sets the event id the stream starts with, the one sent in the
+Last-Event-ID header when reconnecting. */
+func (d *Decoder) SetLastEventID(id string) {
+	d.id = id
+}
+
+/*
+LastEventID returns the last event id the stream set. */
+func (d *Decoder) LastEventID() string {
+	return d.id
+}
+
+/*
+Retry returns the reconnection time the server asked for, zero when it did
+not. */
+func (d *Decoder) Retry() time.Duration {
+	return d.retry
+}
+
+/*
+Next returns the next event of the stream. It returns io.EOF once the stream
+ended, an event that was not finished by an empty line is discarded. */
+func (d *Decoder) Next() (*Event, error) {
+	data := []string{}
+	e := Event{}
+	dispatch := false
+
+	for d.s.Scan() {
+		l := d.s.Text()
+
+		if l == "" {
+			if !dispatch {
+				// An event without data is not dispatched.
+				e = Event{}
+				continue
+			}
+
+			return d.event(e, data), nil
+		}
+
+		if strings.HasPrefix(l, ":") {
+			continue
+		}
+
+		k, v := l, ""
+		if i := strings.IndexByte(l, ':'); i >= 0 {
+			k, v = l[:i], strings.TrimPrefix(l[i+1:], " ")
+		}
+
+		switch k {
+		case "event":
+			e.Type = v
+		case "data":
+			data = append(data, v)
+			dispatch = true
+		case "id":
+			if !strings.ContainsRune(v, 0) {
+				d.id = v
+			}
+		case "retry":
+			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+				d.retry = time.Duration(n) * time.Millisecond
+			}
+		}
+	}
+
+	if err := d.s.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+/*
+event finishes event e with data lines l. */
+func (d *Decoder) event(e Event, l []string) *Event {
+	e.ID = d.id
+	e.Data = strings.Join(l, "\n")
+
+	if e.Type == "" {
+		e.Type = "message"
+	}
+
+	return &e
+}
+
+/*
+scanLines is bufio.ScanLines accepting the CRLF, LF and CR line endings of the
+event stream format. */
+func scanLines(b []byte, eof bool) (int, []byte, error) {
+	for i, c := range b {
+		switch c {
+		case '\n':
+			return i + 1, b[:i], nil
+		case '\r':
+			if i+1 < len(b) {
+				if b[i+1] == '\n' {
+					return i + 2, b[:i], nil
+				}
+
+				return i + 1, b[:i], nil
+			}
+
+			if eof {
+				return i + 1, b[:i], nil
+			}
+
+			// Wait for the next byte to know whether it is a CRLF.
+			return 0, nil, nil
+		}
+	}
+
+	if eof && len(b) > 0 {
+		return len(b), b, nil
+	}
+
+	return 0, nil, nil
+}
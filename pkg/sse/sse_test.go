@@ -0,0 +1,81 @@
+package sse
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Decoder_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []Event
+		retry  time.Duration
+	}{
+		{
+			"success data",
+			"data: hello\n\ndata: a\ndata: b\n\n",
+			[]Event{
+				{Type: "message", Data: "hello"},
+				{Type: "message", Data: "a\nb"},
+			},
+			0,
+		},
+		{
+			"success id and type",
+			"id: 1\nevent: update\ndata: /articles/1\n\ndata: /articles/2\n\n",
+			[]Event{
+				{ID: "1", Type: "update", Data: "/articles/1"},
+				{ID: "1", Type: "message", Data: "/articles/2"},
+			},
+			0,
+		},
+		{
+			"success comments, retry and crlf",
+			": keep alive\r\nretry: 1500\r\n\r\nevent: ping\r\n\r\ndata:x\r\rdata\n\n",
+			[]Event{
+				{Type: "message", Data: "x"},
+				{Type: "message", Data: ""},
+			},
+			1500 * time.Millisecond,
+		},
+		{
+			"success unfinished event discarded",
+			"data: done\n\ndata: half",
+			[]Event{
+				{Type: "message", Data: "done"},
+			},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.stream))
+
+			got := []Event{}
+			for {
+				e, err := d.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got = append(got, *e)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decoder.Next() = %v, want %v", got, tt.want)
+			}
+
+			if d.Retry() != tt.retry {
+				t.Errorf("Decoder.Retry() = %v, want %v", d.Retry(), tt.retry)
+			}
+		})
+	}
+}
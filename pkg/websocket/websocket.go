@@ -0,0 +1,445 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Message types, the opcodes of RFC 6455. */
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+/*
+MaxMessageSize is the size of the largest message a Conn reads. */
+const MaxMessageSize = 16 << 20
+
+/*
+guid is the key suffix of the opening handshake. */
+const guid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+/*
+ErrBadHandshake is returned when the server does not accept the opening
+handshake. */
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+/*
+CloseError is returned when the other end closed the connection. */
+type CloseError struct {
+	Code int
+	Text string
+}
+
+/*
+Error returns the error message. */
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with %d %s", e.Code, e.Text)
+}
+
+/*
+Conn is a WebSocket connection (RFC 6455). Messages are read by one goroutine
+at a time, writes may come from any goroutine. */
+type Conn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool
+
+	mu     sync.Mutex
+	closed bool
+}
+
+/*
+Dial opens a WebSocket connection to uri u, with the ws or wss scheme. Header
+h is sent with the opening handshake, the response to it is returned. wss
+connections trust the system roots, use DialTLS to configure them. */
+func Dial(ctx context.Context, u string, h http.Header) (*Conn, *http.Response, error) {
+	return DialTLS(ctx, u, h, nil)
+}
+
+/*
+DialTLS is Dial with the TLS configuration of wss connections. The server name
+is the host of u unless cfg sets one, and the handshake is always HTTP/1.1,
+whatever protocols cfg offers. */
+func DialTLS(ctx context.Context, u string, h http.Header, cfg *tls.Config) (*Conn, *http.Response, error) {
+	p, err := url.Parse(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	host := p.Host
+	switch p.Scheme {
+	case "ws":
+		if p.Port() == "" {
+			host = net.JoinHostPort(p.Hostname(), "80")
+		}
+	case "wss":
+		if p.Port() == "" {
+			host = net.JoinHostPort(p.Hostname(), "443")
+		}
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %q", p.Scheme)
+	}
+
+	d := net.Dialer{}
+	c, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if dl, ok := ctx.Deadline(); ok {
+		c.SetDeadline(dl)
+	}
+
+	// Neither the TLS handshake nor reading the response watch the context,
+	// the connection times out as soon as it is done instead.
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		select {
+		case <-ctx.Done():
+			c.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	ws, resp, err := open(c, p, h, cfg)
+	close(stop)
+	<-stopped
+
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		c.Close()
+		return nil, resp, err
+	}
+
+	c.SetDeadline(time.Time{})
+
+	return ws, resp, nil
+}
+
+/*
+open secures connection c for wss uris, with configuration cfg, and sends the
+opening handshake for uri u. */
+func open(c net.Conn, u *url.URL, h http.Header, cfg *tls.Config) (*Conn, *http.Response, error) {
+	if u.Scheme != "wss" {
+		return handshake(c, u, h)
+	}
+
+	if cfg == nil {
+		cfg = &tls.Config{}
+	} else {
+		cfg = cfg.Clone()
+	}
+
+	if cfg.ServerName == "" {
+		cfg.ServerName = u.Hostname()
+	}
+	cfg.NextProtos = nil
+
+	tc := tls.Client(c, cfg)
+	if err := tc.Handshake(); err != nil {
+		return nil, nil, err
+	}
+
+	return handshake(tc, u, h)
+}
+
+/*
+handshake sends the opening handshake for uri u over connection c. */
+func handshake(c net.Conn, u *url.URL, h http.Header) (*Conn, *http.Response, error) {
+	k := make([]byte, 16)
+	if _, err := rand.Read(k); err != nil {
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(k)
+
+	hu := *u
+	hu.Scheme = "http"
+	if u.Scheme == "wss" {
+		hu.Scheme = "https"
+	}
+
+	req, err := http.NewRequest("GET", hu.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for k, v := range h {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(c); err != nil {
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(c)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != accept(key) {
+		return nil, resp, ErrBadHandshake
+	}
+
+	ws := Conn{
+		conn:   c,
+		br:     br,
+		client: true,
+	}
+
+	return &ws, resp, nil
+}
+
+/*
+Accept answers the opening handshake of request req, for servers and tests,
+and takes over its connection. */
+func Accept(w http.ResponseWriter, req *http.Request) (*Conn, error) {
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, ErrBadHandshake.Error(), http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket: connection cannot be taken over", http.StatusInternalServerError)
+		return nil, errors.New("websocket: connection cannot be taken over")
+	}
+
+	c, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", accept(key))
+	if err := rw.Flush(); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	ws := Conn{
+		conn: c,
+		br:   rw.Reader,
+	}
+
+	return &ws, nil
+}
+
+/*
+accept returns the Sec-WebSocket-Accept value for key k. */
+func accept(k string) string {
+	h := sha1.Sum([]byte(k + guid))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+/*
+ReadMessage returns the type and the payload of the next text or binary
+message. Pings are answered, and a close from the other end is confirmed and
+returned as a *CloseError. */
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	op := -1
+	msg := []byte{}
+
+	for {
+		fin, fop, b, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch fop {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, b); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			e := CloseError{Code: 1005}
+			if len(b) >= 2 {
+				e.Code = int(binary.BigEndian.Uint16(b))
+				e.Text = string(b[2:])
+			}
+
+			c.writeFrame(CloseMessage, b)
+			c.conn.Close()
+
+			return 0, nil, &e
+		case continuationFrame:
+			if op < 0 {
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if op >= 0 {
+				return 0, nil, errors.New("websocket: unfinished message")
+			}
+			op = fop
+		default:
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %d", fop)
+		}
+
+		if len(msg)+len(b) > MaxMessageSize {
+			return 0, nil, errors.New("websocket: message too large")
+		}
+
+		msg = append(msg, b...)
+		if fin {
+			return op, msg, nil
+		}
+	}
+}
+
+/*
+readFrame reads one frame. */
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	h := make([]byte, 2)
+	if _, err := io.ReadFull(c.br, h); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := h[0]&0x80 != 0
+	op := int(h[0] & 0x0f)
+	masked := h[1]&0x80 != 0
+
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+		if _, err := io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b)
+	}
+
+	if n > MaxMessageSize {
+		return false, 0, nil, errors.New("websocket: message too large")
+	}
+
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.br, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.br, b); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range b {
+			b[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, op, b, nil
+}
+
+/*
+WriteMessage sends a message of type op with payload b. */
+func (c *Conn) WriteMessage(op int, b []byte) error {
+	return c.writeFrame(op, b)
+}
+
+/*
+writeFrame writes one final frame. Frames sent by clients are masked. */
+func (c *Conn) writeFrame(op int, b []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return errors.New("websocket: use of closed connection")
+	}
+
+	f := []byte{0x80 | byte(op)}
+
+	var m byte
+	if c.client {
+		m = 0x80
+	}
+
+	switch n := len(b); {
+	case n < 126:
+		f = append(f, m|byte(n))
+	case n <= 0xffff:
+		f = append(f, m|126, 0, 0)
+		binary.BigEndian.PutUint16(f[2:], uint16(n))
+	default:
+		f = append(f, m|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(f[2:], uint64(n))
+	}
+
+	p := b
+	if c.client {
+		mask := make([]byte, 4)
+		if _, err := rand.Read(mask); err != nil {
+			return err
+		}
+
+		f = append(f, mask...)
+
+		p = make([]byte, len(b))
+		for i := range b {
+			p[i] = b[i] ^ mask[i%4]
+		}
+	}
+
+	if op == CloseMessage {
+		c.closed = true
+	}
+
+	_, err := c.conn.Write(append(f, p...))
+	return err
+}
+
+/*
+Close sends a normal closure to the other end and closes the connection. */
+func (c *Conn) Close() error {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, 1000)
+	c.writeFrame(CloseMessage, b)
+
+	return c.conn.Close()
+}
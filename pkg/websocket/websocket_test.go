@@ -0,0 +1,165 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Conn_Messages(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Accept(w, r)
+		if err != nil {
+			return
+		}
+
+		// Echo every message, then close once "bye" is received.
+		for {
+			op, b, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if string(b) == "bye" {
+				c.Close()
+				return
+			}
+
+			c.WriteMessage(PingMessage, []byte("ping"))
+			c.WriteMessage(op, b)
+		}
+	}))
+	defer s.Close()
+
+	c, _, err := Dial(context.Background(), "ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		op   int
+		b    []byte
+	}{
+		{"success text", TextMessage, []byte("/articles/1")},
+		{"success binary 16 bit length", BinaryMessage, bytes.Repeat([]byte{1, 2, 3}, 1000)},
+		{"success binary 64 bit length", BinaryMessage, bytes.Repeat([]byte{4}, 70000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.WriteMessage(tt.op, tt.b); err != nil {
+				t.Fatal(err)
+			}
+
+			op, b, err := c.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if op != tt.op || !bytes.Equal(b, tt.b) {
+				t.Errorf("Conn.ReadMessage() = %d, %d bytes, want %d, %d bytes", op, len(b), tt.op, len(tt.b))
+			}
+		})
+	}
+
+	t.Run("success close", func(t *testing.T) {
+		c.WriteMessage(TextMessage, []byte("bye"))
+
+		_, _, err := c.ReadMessage()
+
+		var ce *CloseError
+		if !errors.As(err, &ce) || ce.Code != 1000 {
+			t.Errorf("Conn.ReadMessage() expected a normal closure, got %v", err)
+		}
+	})
+}
+
+func Test_Dial_BadHandshake(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+
+	_, resp, err := Dial(context.Background(), "ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != ErrBadHandshake || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Dial() expected ErrBadHandshake with the response, got %v", err)
+	}
+}
+
+func Test_Dial_Cancel(t *testing.T) {
+	// A server that accepts connections and never answers.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	for _, scheme := range []string{"ws", "wss"} {
+		t.Run(scheme, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			errs := make(chan error, 1)
+			go func() {
+				_, _, err := Dial(ctx, scheme+"://"+l.Addr().String()+"/", nil)
+				errs <- err
+			}()
+
+			select {
+			case err := <-errs:
+				if err != context.Canceled {
+					t.Errorf("Dial() expected context.Canceled, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Dial() should stop when the context is done")
+			}
+		})
+	}
+}
+
+func Test_DialTLS(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Accept(w, r)
+		if err != nil {
+			return
+		}
+
+		c.WriteMessage(TextMessage, []byte("hello"))
+		c.Close()
+	}))
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	defer s.Close()
+
+	u := "wss" + strings.TrimPrefix(s.URL, "https")
+
+	if _, _, err := Dial(context.Background(), u, nil); err == nil {
+		t.Errorf("Dial() should not trust the test certificate")
+	}
+
+	cfg := s.Client().Transport.(*http.Transport).TLSClientConfig
+	c, _, err := DialTLS(context.Background(), u, nil, cfg)
+	if err != nil {
+		t.Fatalf("DialTLS() errored with %v when it shouldnt have", err)
+	}
+
+	if _, b, err := c.ReadMessage(); err != nil || string(b) != "hello" {
+		t.Errorf("DialTLS() expected a working connection, got %q, %v", b, err)
+	}
+}
@@ -0,0 +1,73 @@
+package getting
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/identbase/getting/pkg/resource"
+)
+
+/*
+RedirectPolicy decides what the client remembers of permanent redirects. */
+type RedirectPolicy int
+
+const (
+	// KeepURI leaves resources at the uri they were requested with, every
+	// request goes through the redirect again. This is the default.
+	KeepURI RedirectPolicy = iota
+	// UpdateURI moves a resource to the target of a 301 or 308 redirect.
+	// Its Location is updated, it is found under both uris, and when it is
+	// the bookmark the bookmark changes as well.
+	UpdateURI
+)
+
+/*
+WithPermanentRedirects sets what the client does after a GET is permanently
+redirected. */
+func WithPermanentRedirects(p RedirectPolicy) Option {
+	return func(g *Getting) {
+		g.redirectPolicy = p
+	}
+}
+
+/*
+redirected applies the RedirectPolicy to the response to request req. */
+func (g *Getting) redirected(req *http.Request, resp *http.Response) {
+	if g.redirectPolicy != UpdateURI || req.Method != "GET" {
+		return
+	}
+
+	c := resource.Redirects(resp)
+	if len(c) == 0 {
+		return
+	}
+
+	to, ok := resource.PermanentLocation(c)
+	if !ok {
+		return
+	}
+
+	from := c[0].URI.String()
+	u := *to
+
+	g.mu.Lock()
+	if b, err := url.Parse(g.bookmark); err == nil && b.String() == from {
+		g.bookmark = u.String()
+	}
+
+	if r, ok := g.resources.get(from); ok {
+		r.MoveTo(&u)
+
+		if _, ok := g.resources.get(u.String()); !ok {
+			g.resources.add(u.String(), r)
+		}
+	}
+	g.mu.Unlock()
+
+	g.emit(Event{
+		Type:     EventRedirected,
+		Request:  req,
+		Response: resp,
+		URI:      u.String(),
+	})
+}
@@ -0,0 +1,101 @@
+package getting
+
+import (
+	"container/list"
+
+	"github.com/identbase/getting/pkg/resource"
+)
+
+/*
+DefaultResourceLimit is the number of resources a client keeps by default. */
+const DefaultResourceLimit = 1000
+
+/*
+WithResourceLimit sets how many resources the client keeps, so that Go and
+Invalidate find them again. Past the limit the least recently used resources
+are forgotten, except those with watchers. Zero means no limit. */
+func WithResourceLimit(n int) Option {
+	return func(g *Getting) {
+		g.resources.limit = n
+	}
+}
+
+/*
+resources is the set of resources a client handed out, keyed by uri, in
+least recently used order. */
+type resources struct {
+	limit int
+	order *list.List
+	items map[string]*list.Element
+}
+
+/*
+resourceItem is an element of resources. */
+type resourceItem struct {
+	uri string
+	r   *resource.Resource
+}
+
+/*
+newResources creates a new, empty, set of at most n resources. */
+func newResources(n int) *resources {
+	s := resources{
+		limit: n,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}
+
+	return &s
+}
+
+/*
+get returns the resource at uri u, and marks it as recently used. */
+func (s *resources) get(u string) (*resource.Resource, bool) {
+	e, ok := s.items[u]
+	if !ok {
+		return nil, false
+	}
+
+	s.order.MoveToFront(e)
+
+	return e.Value.(*resourceItem).r, true
+}
+
+/*
+add stores resource r at uri u, forgetting the least recently used resources
+without watchers when there are too many. */
+func (s *resources) add(u string, r *resource.Resource) {
+	if e, ok := s.items[u]; ok {
+		e.Value.(*resourceItem).r = r
+		s.order.MoveToFront(e)
+		return
+	}
+
+	s.items[u] = s.order.PushFront(&resourceItem{uri: u, r: r})
+
+	if s.limit <= 0 {
+		return
+	}
+
+	for e := s.order.Back(); e != nil && s.order.Len() > s.limit; {
+		prev := e.Prev()
+
+		if it := e.Value.(*resourceItem); !it.r.Watched() {
+			s.order.Remove(e)
+			delete(s.items, it.uri)
+		}
+
+		e = prev
+	}
+}
+
+/*
+uris returns the uris of the resources in the set. */
+func (s *resources) uris() []string {
+	u := make([]string, 0, len(s.items))
+	for k := range s.items {
+		u = append(u, k)
+	}
+
+	return u
+}
@@ -0,0 +1,188 @@
+package getting
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+RetryPolicy describes how requests that failed with a transient error are
+retried. */
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, it doubles after every
+	// attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay, zero means no cap. A request whose
+	// response asks, with Retry-After, to wait longer is not retried.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of the delay that is randomised.
+	Jitter float64
+	// Retryable decides if a request should be retried, DefaultRetryable is
+	// used when it is nil.
+	Retryable func(req *http.Request, resp *http.Response, err error) bool
+}
+
+/*
+DefaultRetryPolicy returns a RetryPolicy with sensible defaults. */
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+/*
+WithRetry sets the RetryPolicy of the client. */
+func WithRetry(p RetryPolicy) Option {
+	return func(g *Getting) {
+		g.retryPolicy = p
+	}
+}
+
+/*
+DefaultRetryable retries idempotent requests that failed with a network error,
+or with a 429, 502, 503 or 504 response. */
+func DefaultRetryable(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+	default:
+		return false
+	}
+
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+delay returns how long to wait before attempt a (starting at 2). A Retry-After
+header on the response takes precedence over the backoff, it returns false
+when that is longer than MaxDelay. */
+func (p RetryPolicy) delay(a int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return d, p.MaxDelay <= 0 || d <= p.MaxDelay
+		}
+	}
+
+	d := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(a-2)))
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d < 0) {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+
+	return d, true
+}
+
+/*
+retryAfter parses a Retry-After header, which is either a number of seconds or
+an HTTP date. */
+func retryAfter(h string) (time.Duration, bool) {
+	if h == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(h); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+
+		return time.Duration(s) * time.Second, true
+	}
+
+	t, err := http.ParseTime(h)
+	if err != nil {
+		return 0, false
+	}
+
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
+
+/*
+retry sends req until it succeeds, is not retryable, or the policy runs out of
+attempts. */
+func (g *Getting) retry(req *http.Request) (*http.Response, error) {
+	p := g.retryPolicy
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+
+	a := 1
+	for {
+		resp, err := g.send(req)
+
+		if a >= p.MaxAttempts || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		// A request body that cannot be rewound cannot be sent again.
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		// The server asked to wait longer than the policy allows.
+		d, ok := p.delay(a+1, resp)
+		if !ok {
+			return resp, err
+		}
+
+		a++
+
+		g.emit(Event{
+			Type:     EventRetry,
+			Request:  req,
+			Response: resp,
+			Err:      err,
+			Attempt:  a,
+			Delay:    d,
+		})
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		case <-t.C:
+		}
+
+		if req.GetBody != nil {
+			b, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = b
+		}
+	}
+}
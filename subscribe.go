@@ -0,0 +1,356 @@
+package getting
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/identbase/getting/pkg/websocket"
+)
+
+/*
+ErrNoNotificationLink is returned by SubscribeInvalidations when the bookmark
+has no link to a WebSocket endpoint. */
+var ErrNoNotificationLink = errors.New("no websocket notification link")
+
+// stableConnection is how long a notification connection has to stay open for
+// the reconnect backoff to start over.
+const stableConnection = 30 * time.Second
+
+/*
+InvalidationOption configures SubscribeInvalidations. */
+type InvalidationOption func(*invalidationOptions)
+
+/*
+invalidationOptions holds the configuration of SubscribeInvalidations. */
+type invalidationOptions struct {
+	rel     string
+	backoff RetryPolicy
+	parse   func(b []byte) ([]string, error)
+}
+
+/*
+NotificationRel sets the rel of the link to the WebSocket endpoint, "monitor"
+by default. Only links with a ws or wss uri are used. */
+func NotificationRel(rt string) InvalidationOption {
+	return func(o *invalidationOptions) {
+		o.rel = rt
+	}
+}
+
+/*
+ReconnectBackoff sets how the connection is reopened after it broke. Its
+BaseDelay must not be zero. MaxAttempts is the number of failed reconnections
+in a row after which the subscription ends, zero means it never does. A
+connection that breaks within 30 seconds counts as a failed one. */
+func ReconnectBackoff(p RetryPolicy) InvalidationOption {
+	return func(o *invalidationOptions) {
+		o.backoff = p
+	}
+}
+
+/*
+NotificationParser sets how the uris are read from a message,
+ParseInvalidations by default. */
+func NotificationParser(f func(b []byte) ([]string, error)) InvalidationOption {
+	return func(o *invalidationOptions) {
+		o.parse = f
+	}
+}
+
+/*
+ParseInvalidations reads the uris named in a change notification. The message
+is a JSON string, array of strings, or object with a "uri" or "uris" member,
+or else plain text with one uri per line. */
+func ParseInvalidations(b []byte) ([]string, error) {
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return []string{}, nil
+	}
+
+	switch s[0] {
+	case '"':
+		var u string
+		if err := json.Unmarshal([]byte(s), &u); err != nil {
+			return nil, err
+		}
+
+		return []string{u}, nil
+	case '[':
+		u := []string{}
+		if err := json.Unmarshal([]byte(s), &u); err != nil {
+			return nil, err
+		}
+
+		return u, nil
+	case '{':
+		m := struct {
+			URI  string   `json:"uri"`
+			URIs []string `json:"uris"`
+		}{}
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			return nil, err
+		}
+
+		u := m.URIs
+		if m.URI != "" {
+			u = append([]string{m.URI}, u...)
+		}
+
+		return u, nil
+	}
+
+	u := []string{}
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			u = append(u, l)
+		}
+	}
+
+	return u, nil
+}
+
+/*
+SubscribeInvalidations connects to the WebSocket endpoint the bookmark links
+to and invalidates every resource named in the messages it receives, which
+notifies the watchers of those resources. A broken connection is reopened
+with backoff, an EventReconnecting is emitted before every attempt.
+Notifications sent while the connection was down are lost, so once it is
+reopened every resource the client holds is invalidated.
+
+wss connections use the TLS configuration of the client's *http.Transport.
+With any other http.RoundTripper, set by WithTransport for example, they
+trust the system roots. The invalidated resources can be used from other
+goroutines while notifications arrive.
+
+The returned channel receives the error that ended the subscription, the
+context error once it is done, and is then closed. */
+func (g *Getting) SubscribeInvalidations(ctx context.Context, opts ...InvalidationOption) (<-chan error, error) {
+	o := invalidationOptions{
+		rel: "monitor",
+		backoff: RetryPolicy{
+			BaseDelay: 500 * time.Millisecond,
+			MaxDelay:  30 * time.Second,
+			Jitter:    0.5,
+		},
+		parse: ParseInvalidations,
+	}
+
+	for _, v := range opts {
+		v(&o)
+	}
+
+	if o.backoff.BaseDelay <= 0 {
+		return nil, errors.New("reconnect backoff base delay must be positive")
+	}
+
+	u, err := g.notificationURI(ctx, o.rel)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := g.dialNotifications(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go g.notifications(ctx, u, c, o, done)
+
+	return done, nil
+}
+
+/*
+notificationURI returns the uri of the first link of the bookmark with rel rt
+to a WebSocket endpoint. */
+func (g *Getting) notificationURI(ctx context.Context, rt string) (string, error) {
+	r, err := g.Go("")
+	if err != nil {
+		return "", err
+	}
+
+	repr, err := r.Representation(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, l := range repr.GetLinks(rt) {
+		h, err := l.Resolve()
+		if err != nil {
+			continue
+		}
+
+		if strings.HasPrefix(h, "ws://") || strings.HasPrefix(h, "wss://") {
+			return h, nil
+		}
+	}
+
+	return "", ErrNoNotificationLink
+}
+
+/*
+dialNotifications connects to the WebSocket endpoint at uri u, with the
+credentials of the authentication provider of its origin and the TLS
+configuration of the client. */
+func (g *Getting) dialNotifications(ctx context.Context, u string) (*websocket.Conn, error) {
+	h := http.Header{}
+
+	p, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+
+	hu := *p
+	hu.Scheme = strings.Replace(hu.Scheme, "ws", "http", 1)
+
+	if a, ok := g.auth[origin(&hu)]; ok {
+		req, err := http.NewRequest("GET", hu.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := a.Authenticate(req); err != nil {
+			return nil, err
+		}
+
+		h = req.Header
+	}
+
+	c, _, err := websocket.DialTLS(ctx, u, h, g.tlsConfig())
+	return c, err
+}
+
+/*
+tlsConfig returns the TLS configuration of the transport of the client, nil
+when the transport is not an *http.Transport and its configuration is not
+known. */
+func (g *Getting) tlsConfig() *tls.Config {
+	rt := g.client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	if t, ok := rt.(*http.Transport); ok {
+		return t.TLSClientConfig
+	}
+
+	return nil
+}
+
+/*
+notifications reads the messages of connection c to uri u, and reconnects it
+until the context is done or the backoff gives up. */
+func (g *Getting) notifications(ctx context.Context, u string, c *websocket.Conn, o invalidationOptions, done chan<- error) {
+	defer close(done)
+
+	n := 0
+	for {
+		stop := make(chan struct{})
+		go func(c *websocket.Conn) {
+			select {
+			case <-ctx.Done():
+				c.Close()
+			case <-stop:
+			}
+		}(c)
+
+		t := time.Now()
+		err := g.readNotifications(c, o)
+		close(stop)
+
+		if ctx.Err() != nil {
+			done <- ctx.Err()
+			return
+		}
+
+		// A connection that broke right away counts as a failed attempt,
+		// the backoff only starts over once one stayed open.
+		if time.Since(t) >= stableConnection {
+			n = 0
+		}
+
+		c, n, err = g.reconnect(ctx, u, o.backoff, n, err)
+		if err != nil {
+			done <- err
+			return
+		}
+
+		g.invalidateResources()
+	}
+}
+
+/*
+readNotifications invalidates the resources named in the messages of
+connection c, until reading fails. */
+func (g *Getting) readNotifications(c *websocket.Conn, o invalidationOptions) error {
+	for {
+		op, b, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if op != websocket.TextMessage {
+			continue
+		}
+
+		us, err := o.parse(b)
+		if err != nil {
+			continue
+		}
+
+		for _, v := range us {
+			if r, err := g.resolve(v); err == nil {
+				g.Invalidate(r)
+			}
+		}
+	}
+}
+
+/*
+invalidateResources invalidates every resource the client holds. */
+func (g *Getting) invalidateResources() {
+	g.mu.Lock()
+	us := g.resources.uris()
+	g.mu.Unlock()
+
+	for _, v := range us {
+		g.Invalidate(v)
+	}
+}
+
+/*
+reconnect reopens the connection to uri u, which broke with error err after n
+failed attempts in a row, waiting longer after every failed attempt. It
+returns the new connection and the number of attempts it took in all. */
+func (g *Getting) reconnect(ctx context.Context, u string, p RetryPolicy, n int, err error) (*websocket.Conn, int, error) {
+	for n++; p.MaxAttempts == 0 || n <= p.MaxAttempts; n++ {
+		d, _ := p.delay(n+1, nil)
+
+		g.emit(Event{
+			Type:    EventReconnecting,
+			URI:     u,
+			Err:     err,
+			Attempt: n,
+			Delay:   d,
+		})
+
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return nil, n, ctx.Err()
+		}
+
+		var c *websocket.Conn
+		c, err = g.dialNotifications(ctx, u)
+		if err == nil {
+			return c, n, nil
+		}
+	}
+
+	return nil, n, err
+}